@@ -0,0 +1,363 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/malijoe/djanGo-unchained/db"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/schema"
+	"golang.org/x/exp/maps"
+)
+
+var (
+	ErrorPermissionNotFound = errors.New("permission not found")
+)
+
+// ContentType a model of an app that permissions are defined on, e.g. the "order" model of the "orders" app.
+type ContentType struct {
+	ID       uint
+	AppLabel string
+	Model    string
+}
+
+// Permission a permission users are granted directly or through their groups. it is checked as
+// "<app label of its content type>.<codename>", e.g. "orders.change_order" for the codename "change_order".
+type Permission struct {
+	ID            uint
+	Name          string
+	ContentTypeID uint
+	Codename      string
+}
+
+// Group a named set of permissions granted to its members.
+type Group struct {
+	ID   uint
+	Name string
+}
+
+// GroupPermission grants a permission to the members of a group.
+type GroupPermission struct {
+	ID           uint
+	GroupID      uint
+	PermissionID uint
+}
+
+// UserGroup makes a user member of a group.
+type UserGroup struct {
+	ID      uint
+	UserID  uint
+	GroupID uint
+}
+
+// UserPermission grants a permission to a user.
+type UserPermission struct {
+	ID           uint
+	UserID       uint
+	PermissionID uint
+}
+
+// ContentTypeDTO the database representation of a ContentType.
+type ContentTypeDTO struct {
+	bun.BaseModel `bun:"table:django_content_type"`
+
+	ID       uint   `bun:"id,pk,autoincrement"`
+	AppLabel string `bun:"app_label,notnull,unique:app_label_model"`
+	Model    string `bun:"model,notnull,unique:app_label_model"`
+}
+
+func (ContentTypeDTO) FromEntity(c ContentType) any {
+	return ContentTypeDTO{
+		ID:       c.ID,
+		AppLabel: c.AppLabel,
+		Model:    c.Model,
+	}
+}
+
+func (d ContentTypeDTO) ToEntity() ContentType {
+	return ContentType{
+		ID:       d.ID,
+		AppLabel: d.AppLabel,
+		Model:    d.Model,
+	}
+}
+
+// PermissionDTO the database representation of a Permission.
+type PermissionDTO struct {
+	bun.BaseModel `bun:"table:auth_permission"`
+
+	ID            uint   `bun:"id,pk,autoincrement"`
+	Name          string `bun:"name,notnull"`
+	ContentTypeID uint   `bun:"content_type_id,notnull,unique:content_type_codename"`
+	Codename      string `bun:"codename,notnull,unique:content_type_codename"`
+}
+
+func (PermissionDTO) FromEntity(p Permission) any {
+	return PermissionDTO{
+		ID:            p.ID,
+		Name:          p.Name,
+		ContentTypeID: p.ContentTypeID,
+		Codename:      p.Codename,
+	}
+}
+
+func (d PermissionDTO) ToEntity() Permission {
+	return Permission{
+		ID:            d.ID,
+		Name:          d.Name,
+		ContentTypeID: d.ContentTypeID,
+		Codename:      d.Codename,
+	}
+}
+
+// GroupDTO the database representation of a Group.
+type GroupDTO struct {
+	bun.BaseModel `bun:"table:auth_group"`
+
+	ID   uint   `bun:"id,pk,autoincrement"`
+	Name string `bun:"name,unique,notnull"`
+}
+
+func (GroupDTO) FromEntity(g Group) any {
+	return GroupDTO{
+		ID:   g.ID,
+		Name: g.Name,
+	}
+}
+
+func (d GroupDTO) ToEntity() Group {
+	return Group{
+		ID:   d.ID,
+		Name: d.Name,
+	}
+}
+
+// GroupPermissionDTO the database representation of a GroupPermission.
+type GroupPermissionDTO struct {
+	bun.BaseModel `bun:"table:auth_group_permissions"`
+
+	ID           uint `bun:"id,pk,autoincrement"`
+	GroupID      uint `bun:"group_id,notnull"`
+	PermissionID uint `bun:"permission_id,notnull"`
+}
+
+func (GroupPermissionDTO) FromEntity(g GroupPermission) any {
+	return GroupPermissionDTO{
+		ID:           g.ID,
+		GroupID:      g.GroupID,
+		PermissionID: g.PermissionID,
+	}
+}
+
+func (d GroupPermissionDTO) ToEntity() GroupPermission {
+	return GroupPermission{
+		ID:           d.ID,
+		GroupID:      d.GroupID,
+		PermissionID: d.PermissionID,
+	}
+}
+
+// UserGroupDTO the database representation of a UserGroup.
+type UserGroupDTO struct {
+	bun.BaseModel `bun:"table:auth_user_groups"`
+
+	ID      uint `bun:"id,pk,autoincrement"`
+	UserID  uint `bun:"user_id,notnull"`
+	GroupID uint `bun:"group_id,notnull"`
+}
+
+func (UserGroupDTO) FromEntity(u UserGroup) any {
+	return UserGroupDTO{
+		ID:      u.ID,
+		UserID:  u.UserID,
+		GroupID: u.GroupID,
+	}
+}
+
+func (d UserGroupDTO) ToEntity() UserGroup {
+	return UserGroup{
+		ID:      d.ID,
+		UserID:  d.UserID,
+		GroupID: d.GroupID,
+	}
+}
+
+// UserPermissionDTO the database representation of a UserPermission.
+type UserPermissionDTO struct {
+	bun.BaseModel `bun:"table:auth_user_user_permissions"`
+
+	ID           uint `bun:"id,pk,autoincrement"`
+	UserID       uint `bun:"user_id,notnull"`
+	PermissionID uint `bun:"permission_id,notnull"`
+}
+
+func (UserPermissionDTO) FromEntity(u UserPermission) any {
+	return UserPermissionDTO{
+		ID:           u.ID,
+		UserID:       u.UserID,
+		PermissionID: u.PermissionID,
+	}
+}
+
+func (d UserPermissionDTO) ToEntity() UserPermission {
+	return UserPermission{
+		ID:           d.ID,
+		UserID:       d.UserID,
+		PermissionID: d.PermissionID,
+	}
+}
+
+// PermissionRepositories the repositories a PermissionStore keeps permissions, groups and their grants in.
+type PermissionRepositories struct {
+	ContentTypes     db.BaseRepository[ContentType]
+	Permissions      db.BaseRepository[Permission]
+	Groups           db.BaseRepository[Group]
+	GroupPermissions db.BaseRepository[GroupPermission]
+	UserGroups       db.BaseRepository[UserGroup]
+	UserPermissions  db.BaseRepository[UserPermission]
+}
+
+// NewPermissionRepositories creates the repositories of a PermissionStore on Django's `django_content_type`,
+// `auth_permission`, `auth_group`, `auth_group_permissions`, `auth_user_groups` and `auth_user_user_permissions` tables.
+func NewPermissionRepositories(conn *sql.DB, dialect schema.Dialect) PermissionRepositories {
+	return PermissionRepositories{
+		ContentTypes:     db.NewRepository[ContentTypeDTO, ContentType](conn, dialect),
+		Permissions:      db.NewRepository[PermissionDTO, Permission](conn, dialect),
+		Groups:           db.NewRepository[GroupDTO, Group](conn, dialect),
+		GroupPermissions: db.NewRepository[GroupPermissionDTO, GroupPermission](conn, dialect),
+		UserGroups:       db.NewRepository[UserGroupDTO, UserGroup](conn, dialect),
+		UserPermissions:  db.NewRepository[UserPermissionDTO, UserPermission](conn, dialect),
+	}
+}
+
+// PermissionStore grants permissions to users and groups, and resolves the permissions of users.
+type PermissionStore struct {
+	repos PermissionRepositories
+}
+
+func NewPermissionStore(repos PermissionRepositories) *PermissionStore {
+	return &PermissionStore{
+		repos: repos,
+	}
+}
+
+// UserPermissions returns the codenames of the permissions granted to the user, directly or through its groups.
+func (s *PermissionStore) UserPermissions(ctx context.Context, userID uint) ([]string, error) {
+	ids := make(map[uint]struct{})
+
+	userPermissions, err := s.repos.UserPermissions.Find(ctx, db.Equal("user_id", userID))
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range userPermissions {
+		ids[p.PermissionID] = struct{}{}
+	}
+
+	userGroups, err := s.repos.UserGroups.Find(ctx, db.Equal("user_id", userID))
+	if err != nil {
+		return nil, err
+	}
+	if len(userGroups) > 0 {
+		groupIDs := make([]uint, len(userGroups))
+		for i, g := range userGroups {
+			groupIDs[i] = g.GroupID
+		}
+		groupPermissions, err := s.repos.GroupPermissions.Find(ctx, db.In("group_id", groupIDs))
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range groupPermissions {
+			ids[p.PermissionID] = struct{}{}
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	permissions, err := s.repos.Permissions.Find(ctx, db.In("id", maps.Keys(ids)))
+	if err != nil {
+		return nil, err
+	}
+	appLabels, err := s.appLabels(ctx, permissions)
+	if err != nil {
+		return nil, err
+	}
+	codenames := make([]string, 0, len(permissions))
+	for _, p := range permissions {
+		if appLabel, ok := appLabels[p.ContentTypeID]; ok {
+			codenames = append(codenames, appLabel+"."+p.Codename)
+		}
+	}
+	return codenames, nil
+}
+
+// appLabels returns the app labels of the content types of the permissions, by content type id.
+func (s *PermissionStore) appLabels(ctx context.Context, permissions []Permission) (map[uint]string, error) {
+	ids := make(map[uint]struct{}, len(permissions))
+	for _, p := range permissions {
+		ids[p.ContentTypeID] = struct{}{}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	contentTypes, err := s.repos.ContentTypes.Find(ctx, db.In("id", maps.Keys(ids)))
+	if err != nil {
+		return nil, err
+	}
+	appLabels := make(map[uint]string, len(contentTypes))
+	for _, c := range contentTypes {
+		appLabels[c.ID] = c.AppLabel
+	}
+	return appLabels, nil
+}
+
+// AddUserPermission grants the permission with the given codename to the user.
+func (s *PermissionStore) AddUserPermission(ctx context.Context, userID uint, codename string) error {
+	permission, err := s.permission(ctx, codename)
+	if err != nil {
+		return err
+	}
+	_, err = s.repos.UserPermissions.Save(ctx, UserPermission{UserID: userID, PermissionID: permission.ID})
+	return err
+}
+
+// AddGroupPermission grants the permission with the given codename to the members of the group.
+func (s *PermissionStore) AddGroupPermission(ctx context.Context, groupID uint, codename string) error {
+	permission, err := s.permission(ctx, codename)
+	if err != nil {
+		return err
+	}
+	_, err = s.repos.GroupPermissions.Save(ctx, GroupPermission{GroupID: groupID, PermissionID: permission.ID})
+	return err
+}
+
+// AddUserToGroup makes the user member of the group.
+func (s *PermissionStore) AddUserToGroup(ctx context.Context, userID, groupID uint) error {
+	_, err := s.repos.UserGroups.Save(ctx, UserGroup{UserID: userID, GroupID: groupID})
+	return err
+}
+
+// permission finds the permission checked as codename, "<app label>.<codename>".
+func (s *PermissionStore) permission(ctx context.Context, codename string) (*Permission, error) {
+	appLabel, name, ok := strings.Cut(codename, ".")
+	if !ok {
+		return nil, fmt.Errorf("%w: %q is not of the form <app label>.<codename>", ErrorPermissionNotFound, codename)
+	}
+	permissions, err := s.repos.Permissions.Find(ctx, db.Equal("codename", name))
+	if err != nil {
+		return nil, err
+	}
+	appLabels, err := s.appLabels(ctx, permissions)
+	if err != nil {
+		return nil, err
+	}
+	for i := range permissions {
+		if appLabels[permissions[i].ContentTypeID] == appLabel {
+			return &permissions[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %q", ErrorPermissionNotFound, codename)
+}
@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/malijoe/djanGo-unchained/db"
+)
+
+// newFindRepository returns a repository finding the entities matching the field compared by the specification.
+func newFindRepository[T any](field func(T) any, entities ...T) db.BaseRepository[T] {
+	return db.NewMockRepository[T](db.WithFindFn(func(query db.Specification) ([]T, error) {
+		var found []T
+		for _, e := range entities {
+			for _, v := range query.Values() {
+				if v == field(e) {
+					found = append(found, e)
+					break
+				}
+			}
+		}
+		return found, nil
+	}))
+}
+
+func newPermissionRepositories() PermissionRepositories {
+	return PermissionRepositories{
+		ContentTypes: newFindRepository(func(c ContentType) any { return c.ID },
+			ContentType{ID: 1, AppLabel: "orders", Model: "order"},
+			ContentType{ID: 2, AppLabel: "invoices", Model: "invoice"},
+		),
+		Permissions: newFindRepository(func(p Permission) any { return p.ID },
+			Permission{ID: 1, ContentTypeID: 1, Codename: "view_order"},
+			Permission{ID: 2, ContentTypeID: 1, Codename: "change_order"},
+			Permission{ID: 3, ContentTypeID: 1, Codename: "delete_order"},
+			Permission{ID: 4, ContentTypeID: 2, Codename: "view_invoice"},
+		),
+		UserPermissions: newFindRepository(func(p UserPermission) any { return p.UserID },
+			UserPermission{ID: 1, UserID: 1, PermissionID: 1},
+			UserPermission{ID: 2, UserID: 1, PermissionID: 4},
+		),
+		UserGroups: newFindRepository(func(g UserGroup) any { return g.UserID },
+			UserGroup{ID: 1, UserID: 1, GroupID: 1},
+		),
+		GroupPermissions: newFindRepository(func(p GroupPermission) any { return p.GroupID },
+			GroupPermission{ID: 1, GroupID: 1, PermissionID: 2},
+			GroupPermission{ID: 2, GroupID: 2, PermissionID: 3},
+		),
+	}
+}
+
+func TestHasPerm(t *testing.T) {
+	store := NewPermissionStore(newPermissionRepositories())
+	provider := NewProvider(nil, WithPermissionStore(store))
+	clerk := &user{User: &User{ID: 1, IsActive: true}, provider: provider}
+	admin := &user{User: &User{ID: 2, IsActive: true, IsSuperuser: true}, provider: provider}
+
+	tests := []struct {
+		user     *user
+		codename string
+		want     bool
+	}{
+		{user: clerk, codename: "orders.view_order", want: true},
+		{user: clerk, codename: "orders.change_order", want: true},
+		{user: clerk, codename: "orders.delete_order", want: false},
+		{user: clerk, codename: "invoices.view_invoice", want: true},
+		{user: clerk, codename: "view_order", want: false},
+		{user: clerk, codename: "invoices.view_order", want: false},
+		{user: admin, codename: "orders.delete_order", want: true},
+	}
+	for _, test := range tests {
+		if got := test.user.HasPerm(test.codename); got != test.want {
+			t.Errorf("user %d %s: got %t, want %t", test.user.User.ID, test.codename, got, test.want)
+		}
+	}
+}
+
+func TestAddUserPermission(t *testing.T) {
+	repos := newPermissionRepositories()
+	repos.Permissions = newFindRepository(func(p Permission) any { return p.Codename },
+		Permission{ID: 3, ContentTypeID: 1, Codename: "delete_order"},
+		Permission{ID: 5, ContentTypeID: 2, Codename: "delete_order"},
+	)
+	var saved UserPermission
+	repos.UserPermissions = db.NewMockRepository[UserPermission](db.WithSaveFn(func(p *UserPermission) (*UserPermission, error) {
+		saved = *p
+		return p, nil
+	}))
+	store := NewPermissionStore(repos)
+
+	if err := store.AddUserPermission(context.Background(), 1, "invoices.delete_order"); err != nil {
+		t.Fatalf("AddUserPermission returned error %v", err)
+	}
+	if saved.UserID != 1 || saved.PermissionID != 5 {
+		t.Errorf("AddUserPermission saved %+v, want permission 5 granted to user 1", saved)
+	}
+	for _, codename := range []string{"delete_order", "shipping.delete_order", "orders.view_order"} {
+		if err := store.AddUserPermission(context.Background(), 1, codename); !errors.Is(err, ErrorPermissionNotFound) {
+			t.Errorf("AddUserPermission(%q) got error %v, want %v", codename, err, ErrorPermissionNotFound)
+		}
+	}
+}
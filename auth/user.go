@@ -0,0 +1,269 @@
+// Package auth provides a default user entity, stored in Django's `auth_user` table,
+// and a django.UserProvider backed by a repository of it.
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	django "github.com/malijoe/djanGo-unchained"
+	"github.com/malijoe/djanGo-unchained/db"
+	"github.com/malijoe/djanGo-unchained/hashers"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/schema"
+)
+
+var (
+	ErrorUserNotFound = errors.New("user not found")
+	ErrorInactiveUser = errors.New("user is inactive")
+)
+
+// User the default user entity.
+type User struct {
+	ID        uint
+	Username  string
+	Email     string
+	FirstName string
+	LastName  string
+	// Password the hash of the password, see SetPassword.
+	Password    string
+	IsActive    bool
+	IsStaff     bool
+	IsSuperuser bool
+	LastLogin   *time.Time
+	DateJoined  time.Time
+}
+
+// SetPassword hashes the password with the preferred hasher.
+func (u *User) SetPassword(password string) error {
+	encoded, err := hashers.MakePassword(password)
+	if err != nil {
+		return err
+	}
+	u.Password = encoded
+	return nil
+}
+
+// CheckPassword returns nil if the password matches the user's password hash.
+func (u *User) CheckPassword(password string) error {
+	return hashers.CheckPassword(password, u.Password)
+}
+
+// UserDTO the database representation of a User.
+type UserDTO struct {
+	bun.BaseModel `bun:"table:auth_user"`
+
+	ID          uint        `bun:"id,pk,autoincrement"`
+	Username    string      `bun:"username,unique,notnull"`
+	Email       string      `bun:"email,notnull"`
+	FirstName   string      `bun:"first_name,notnull"`
+	LastName    string      `bun:"last_name,notnull"`
+	Password    string      `bun:"password,notnull"`
+	IsActive    bool        `bun:"is_active,notnull"`
+	IsStaff     bool        `bun:"is_staff,notnull"`
+	IsSuperuser bool        `bun:"is_superuser,notnull"`
+	LastLogin   db.NullTime `bun:"last_login"`
+	DateJoined  time.Time   `bun:"date_joined,notnull"`
+}
+
+func (UserDTO) FromEntity(u User) any {
+	dto := UserDTO{
+		ID:          u.ID,
+		Username:    u.Username,
+		Email:       u.Email,
+		FirstName:   u.FirstName,
+		LastName:    u.LastName,
+		Password:    u.Password,
+		IsActive:    u.IsActive,
+		IsStaff:     u.IsStaff,
+		IsSuperuser: u.IsSuperuser,
+		DateJoined:  u.DateJoined,
+	}
+	if u.LastLogin != nil {
+		dto.LastLogin = db.NullTime{Time: *u.LastLogin, Valid: true}
+	}
+	return dto
+}
+
+func (d UserDTO) ToEntity() User {
+	u := User{
+		ID:          d.ID,
+		Username:    d.Username,
+		Email:       d.Email,
+		FirstName:   d.FirstName,
+		LastName:    d.LastName,
+		Password:    d.Password,
+		IsActive:    d.IsActive,
+		IsStaff:     d.IsStaff,
+		IsSuperuser: d.IsSuperuser,
+		DateJoined:  d.DateJoined,
+	}
+	if d.LastLogin.Valid {
+		lastLogin := d.LastLogin.Time
+		u.LastLogin = &lastLogin
+	}
+	return u
+}
+
+// NewRepository creates a user repository on the `auth_user` table.
+func NewRepository(conn *sql.DB, dialect schema.Dialect) db.Repository[User] {
+	return db.NewRepository[UserDTO, User](conn, dialect)
+}
+
+var _ django.UserProvider = (*Provider)(nil)
+
+// Provider a django.UserProvider finding active users in a repository.
+type Provider struct {
+	repo        db.BaseRepository[User]
+	permissions *PermissionStore
+	now         func() time.Time
+}
+
+type ProviderOpt func(*Provider)
+
+// WithPermissionStore resolves the permissions of the users found by the provider through the store.
+// without it, only superusers are granted permissions.
+func WithPermissionStore(store *PermissionStore) ProviderOpt {
+	return func(p *Provider) {
+		p.permissions = store
+	}
+}
+
+func NewProvider(repo db.BaseRepository[User], opts ...ProviderOpt) *Provider {
+	p := Provider{
+		repo: repo,
+		now:  time.Now,
+	}
+	for _, opt := range opts {
+		opt(&p)
+	}
+	return &p
+}
+
+func (p *Provider) GetUser(id uint) (django.User, error) {
+	u, err := p.repo.Get(context.Background(), id)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && u == nil) {
+		return nil, ErrorUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p.user(u)
+}
+
+// FindUser finds the user by username, ignoring case. an exact match is preferred
+// when several usernames only differ by case.
+func (p *Provider) FindUser(username string) (django.User, error) {
+	users, err := p.repo.Find(context.Background(), db.Equal("lower(username)", strings.ToLower(username)))
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, ErrorUserNotFound
+	}
+	found := &users[0]
+	for i := range users {
+		if users[i].Username == username {
+			found = &users[i]
+			break
+		}
+	}
+	return p.user(found)
+}
+
+func (p *Provider) user(u *User) (django.User, error) {
+	if !u.IsActive {
+		return nil, ErrorInactiveUser
+	}
+	return &user{
+		User:     u,
+		provider: p,
+	}, nil
+}
+
+// Entity returns the User entity of an authenticated django.User found by a Provider.
+func Entity(u django.User) (*User, bool) {
+	found, ok := u.(*user)
+	if !ok {
+		return nil, false
+	}
+	return found.User, true
+}
+
+var (
+	_ django.IdentifiedUser  = (*user)(nil)
+	_ django.PermissionsUser = (*user)(nil)
+)
+
+// user adapts a User to the django.User interface.
+type user struct {
+	*User
+	provider *Provider
+	// permissions caches the codenames of the user's permissions once resolved.
+	permissions map[string]struct{}
+}
+
+func (u *user) ID() uint {
+	return u.User.ID
+}
+
+func (u *user) Username() string {
+	return u.User.Username
+}
+
+func (u *user) IsAuthenticated() bool {
+	return true
+}
+
+func (u *user) IsAdmin() bool {
+	return u.IsStaff
+}
+
+// ValidatePassword checks the password and records the login in last_login,
+// rehashing the password if its hash uses an outdated algorithm. only these columns are updated when the
+// repository is a db.ColumnUpdater, the whole user otherwise.
+func (u *user) ValidatePassword(password string) error {
+	if err := u.CheckPassword(password); err != nil {
+		return err
+	}
+	now := u.provider.now()
+	u.LastLogin = &now
+	columns := []string{"last_login"}
+	if hashers.MustUpdate(u.Password) {
+		if err := u.SetPassword(password); err != nil {
+			return err
+		}
+		columns = append(columns, "password")
+	}
+	if updater, ok := u.provider.repo.(db.ColumnUpdater[User]); ok {
+		_, err := updater.UpdateColumns(context.Background(), *u.User, columns...)
+		return err
+	}
+	_, err := u.provider.repo.Update(context.Background(), *u.User)
+	return err
+}
+
+// HasPerm returns true if the user is a superuser or was granted the permission, directly or through its groups.
+func (u *user) HasPerm(codename string) bool {
+	if u.IsSuperuser {
+		return true
+	}
+	if u.provider.permissions == nil {
+		return false
+	}
+	if u.permissions == nil {
+		codenames, err := u.provider.permissions.UserPermissions(context.Background(), u.User.ID)
+		if err != nil {
+			return false
+		}
+		u.permissions = make(map[string]struct{}, len(codenames))
+		for _, codename := range codenames {
+			u.permissions[codename] = struct{}{}
+		}
+	}
+	_, ok := u.permissions[codename]
+	return ok
+}
@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	django "github.com/malijoe/djanGo-unchained"
+	"github.com/malijoe/djanGo-unchained/db"
+	"github.com/malijoe/djanGo-unchained/hashers"
+)
+
+// newUserRepository returns a repository of the users, updating them in place, and the users.
+func newUserRepository(users ...User) (*db.MockRepository[User], []User) {
+	return db.NewMockRepository[User](
+		db.WithFindFn(func(query db.Specification) ([]User, error) {
+			var found []User
+			for _, u := range users {
+				if strings.ToLower(u.Username) == query.Values()[0] {
+					found = append(found, u)
+				}
+			}
+			return found, nil
+		}),
+		db.WithUpdateColumnsFn(func(u *User, columns []string) (*User, error) {
+			stored := &users[u.ID-1]
+			for _, column := range columns {
+				switch column {
+				case "last_login":
+					stored.LastLogin = u.LastLogin
+				case "password":
+					stored.Password = u.Password
+				}
+			}
+			return stored, nil
+		}),
+	), users
+}
+
+func TestProvider(t *testing.T) {
+	defer func(h []hashers.Hasher) { hashers.PASSWORD_HASHERS = h }(hashers.PASSWORD_HASHERS)
+	hashers.PASSWORD_HASHERS = []hashers.Hasher{hashers.NewPBKDF2SHA256Hasher(1000), hashers.NewBCryptHasher(4)}
+
+	alice := User{ID: 1, Username: "Alice", IsActive: true}
+	legacy, _ := hashers.NewBCryptHasher(4).Encode("secret")
+	alice.Password = legacy
+	bob := User{ID: 2, Username: "bob"}
+	_ = bob.SetPassword("secret")
+	repo, stored := newUserRepository(alice, bob)
+	now := time.Date(2022, 9, 1, 12, 0, 0, 0, time.UTC)
+	provider := NewProvider(repo)
+	provider.now = func() time.Time { return now }
+
+	u, err := provider.FindUser("ALICE")
+	if err != nil {
+		t.Fatalf("find alice: %v", err)
+	}
+	if id := u.(django.IdentifiedUser).ID(); id != 1 || !u.IsAuthenticated() || u.IsAdmin() {
+		t.Errorf("got user %d authenticated %t admin %t, want 1 true false", id, u.IsAuthenticated(), u.IsAdmin())
+	}
+	if err = u.ValidatePassword("wrong"); !errors.Is(err, hashers.ErrorPasswordMismatch) {
+		t.Errorf("wrong password: got %v, want %v", err, hashers.ErrorPasswordMismatch)
+	}
+	if repo.UpdateColumnsInvoked {
+		t.Error("wrong password: got last_login updated")
+	}
+	// alice is deactivated after being loaded: recording her login must not reactivate her.
+	stored[0].IsActive = false
+	if err = u.ValidatePassword("secret"); err != nil {
+		t.Fatalf("validate password: %v", err)
+	}
+	updated := stored[0]
+	if updated.LastLogin == nil || !updated.LastLogin.Equal(now) {
+		t.Errorf("got last_login %v, want %v", updated.LastLogin, now)
+	}
+	if algorithm, _, _ := strings.Cut(updated.Password, "$"); algorithm != "pbkdf2_sha256" {
+		t.Errorf("got password rehashed with %s, want pbkdf2_sha256", algorithm)
+	}
+	if updated.IsActive || repo.UpdateInvoked {
+		t.Error("validate password: got the whole user updated, want only last_login and password")
+	}
+
+	// repositories unable to update columns get the whole user updated.
+	repo, _ = newUserRepository(User{ID: 1, Username: "carol", Password: updated.Password, IsActive: true})
+	if u, err = NewProvider(struct{ db.BaseRepository[User] }{repo}).FindUser("carol"); err != nil {
+		t.Fatalf("find carol: %v", err)
+	}
+	if err = u.ValidatePassword("secret"); err != nil || !repo.UpdateInvoked || repo.UpdateColumnsInvoked {
+		t.Errorf("validate password without a column updater: got error %v, update %t, update columns %t, want only update",
+			err, repo.UpdateInvoked, repo.UpdateColumnsInvoked)
+	}
+
+	if _, err = provider.FindUser("bob"); !errors.Is(err, ErrorInactiveUser) {
+		t.Errorf("inactive user: got %v, want %v", err, ErrorInactiveUser)
+	}
+	if _, err = provider.FindUser("carol"); !errors.Is(err, ErrorUserNotFound) {
+		t.Errorf("unknown user: got %v, want %v", err, ErrorUserNotFound)
+	}
+}
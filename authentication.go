@@ -1,14 +1,19 @@
 package django
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/alexedwards/scs/v2"
 )
 
 var (
 	ErrorCouldNotAuthenticate = errors.New("could not authenticate")
+	ErrorNoSession            = errors.New("no session: the request did not go through SessionMiddleware")
+	ErrorNotConfigured        = errors.New("authentication class is not configured: create it with its constructor")
 )
 
 type AuthenticationClass interface {
@@ -17,7 +22,7 @@ type AuthenticationClass interface {
 	Authenticate(*Request) error
 	// AuthenticateHeader
 	// returns a string to be used as the value of the `WWW-Authenticate`
-	// header in a `
+	// header in a `401 Unauthenticated` response
 	AuthenticateHeader() string
 }
 
@@ -26,6 +31,8 @@ type UserProvider interface {
 	FindUser(username string) (User, error)
 }
 
+// DEFAULT_AUTHENTICATION_CLASSES the classes WithAuthentication uses when given none. the zero value classes
+// authenticate no request; replace them with configured ones, e.g. NewBasicAuthentication(provider).
 var DEFAULT_AUTHENTICATION_CLASSES = []AuthenticationClass{
 	&BasicAuthentication{},
 	&SessionAuthentication{},
@@ -61,6 +68,9 @@ func NewBasicAuthentication(provider UserProvider, realm ...string) Authenticati
 }
 
 func (a *BasicAuthentication) Authenticate(request *Request) error {
+	if a.provider == nil {
+		return fmt.Errorf("%w: %v", ErrorCouldNotAuthenticate, ErrorNotConfigured)
+	}
 	username, password, ok := request.BasicAuth()
 	if ok {
 		u, err := a.provider.FindUser(username)
@@ -77,7 +87,10 @@ func (a *BasicAuthentication) Authenticate(request *Request) error {
 }
 
 func (a *BasicAuthentication) AuthenticateHeader() string {
-	return fmt.Sprintf("Basic realm=%s", a.realm)
+	if a.realm == "" {
+		return `Basic realm="api"`
+	}
+	return fmt.Sprintf("Basic realm=%q", a.realm)
 }
 
 type SessionAuthentication struct {
@@ -94,8 +107,16 @@ func NewSessionAuthentication(provider UserProvider, manager *scs.SessionManager
 	return &auth
 }
 
+// Authenticate authenticates the user stored in the session. unsafe requests must also pass CSRF validation,
+// failing with a 403 APIError otherwise.
 func (a *SessionAuthentication) Authenticate(request *Request) error {
-	if ctxId := a.manager.Get(request.Context(), "userID"); ctxId != nil {
+	if a.provider == nil || a.manager == nil {
+		return fmt.Errorf("%w: %v", ErrorCouldNotAuthenticate, ErrorNotConfigured)
+	}
+	if request.sessions == nil {
+		return fmt.Errorf("%w: %v", ErrorCouldNotAuthenticate, ErrorNoSession)
+	}
+	if ctxId := a.manager.Get(request.Context(), sessionUserKey); ctxId != nil {
 		id, ok := ctxId.(uint)
 		if !ok {
 			panic("user id value in context is not of type uint")
@@ -105,20 +126,39 @@ func (a *SessionAuthentication) Authenticate(request *Request) error {
 			return err
 		}
 		request.User = u
+		if err := enforceCSRF(request); err != nil {
+			return err
+		}
 		return nil
 	}
 	return fmt.Errorf("%w: no session found", ErrorCouldNotAuthenticate)
 }
 
+// Token an API key identifying the user it was issued to.
+type Token struct {
+	ID      uint
+	Key     string
+	UserID  uint
+	Created time.Time
+}
+
+// TokenStore looks up the tokens accepted by TokenAuthentication.
+type TokenStore interface {
+	// Lookup returns the token with the given key, or an error if no such token exists.
+	Lookup(ctx context.Context, key string) (*Token, error)
+}
+
 type TokenAuthentication struct {
 	BaseAuthentication
 	keyword  string
 	provider UserProvider
+	store    TokenStore
 }
 
-func NewTokenAuthentication(provider UserProvider, keyword ...string) AuthenticationClass {
+func NewTokenAuthentication(provider UserProvider, store TokenStore, keyword ...string) AuthenticationClass {
 	auth := TokenAuthentication{
 		provider: provider,
+		store:    store,
 		keyword:  "Token",
 	}
 	if len(keyword) > 0 {
@@ -127,10 +167,32 @@ func NewTokenAuthentication(provider UserProvider, keyword ...string) Authentica
 	return &auth
 }
 
+// Authenticate authenticates requests carrying an `Authorization: <keyword> <token>` header.
 func (a *TokenAuthentication) Authenticate(request *Request) error {
+	if a.provider == nil || a.store == nil {
+		return fmt.Errorf("%w: %v", ErrorCouldNotAuthenticate, ErrorNotConfigured)
+	}
 	auth := request.Request.Header.Get("Authorization")
 	if auth != "" {
-
+		keyword, key, _ := strings.Cut(auth, " ")
+		if !strings.EqualFold(keyword, a.keyword) {
+			return fmt.Errorf("%w: authorization header does not use the %s keyword", ErrorCouldNotAuthenticate, a.keyword)
+		}
+		key = strings.TrimSpace(key)
+		if key == "" || strings.ContainsAny(key, " \t") {
+			return fmt.Errorf("%w: invalid token header", ErrorCouldNotAuthenticate)
+		}
+		token, err := a.store.Lookup(request.Context(), key)
+		if err != nil {
+			return fmt.Errorf("%w: invalid token: %v", ErrorCouldNotAuthenticate, err)
+		}
+		u, err := a.provider.GetUser(token.UserID)
+		if err != nil {
+			return err
+		}
+		request.User = u
+		request.Auth = key
+		return nil
 	}
 	return fmt.Errorf("%w: missing authorization header", ErrorCouldNotAuthenticate)
 }
@@ -0,0 +1,140 @@
+package django
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unchallenged fails to authenticate every request without providing a `WWW-Authenticate` header.
+type unchallenged struct {
+	BaseAuthentication
+}
+
+func (unchallenged) Authenticate(_ *Request) error {
+	return ErrorCouldNotAuthenticate
+}
+
+func TestAuthentication(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	provider := testUserProvider{1: {id: 1, username: "alice", password: "secret"}}
+	whoami := func(ctx *gin.Context, _ *bookSerializer) (int, any, error) {
+		return http.StatusOK, GetRequest(ctx).User.Username(), nil
+	}
+	eng := gin.New()
+	Path(eng, "/basic", NewHandler[bookSerializer](
+		WithAuthentication[bookSerializer](NewBasicAuthentication(provider, "books"), NewTokenAuthentication(provider, nil)),
+	).Get(whoami), "")
+	Path(eng, "/anonymous", NewHandler[bookSerializer](
+		WithAuthentication[bookSerializer](NewBasicAuthentication(provider)),
+		WithPermissions[bookSerializer](AllowAny{}),
+	).Get(whoami), "")
+	Path(eng, "/unchallenged", NewHandler[bookSerializer](WithAuthentication[bookSerializer](unchallenged{})).Get(whoami), "")
+	Path(eng, "/unchallenged-permissions", NewHandler[bookSerializer](
+		WithAuthentication[bookSerializer](unchallenged{}),
+		WithPermissions[bookSerializer](IsAuthenticated{}),
+	).Get(whoami), "")
+	Path(eng, "/defaults", NewHandler[bookSerializer](WithAuthentication[bookSerializer]()).Get(whoami), "")
+
+	tests := []struct {
+		name               string
+		path               string
+		username, password string
+		code               int
+		response           string
+		authenticate       string
+	}{
+		{name: "authenticated", path: "/basic", username: "alice", password: "secret", code: http.StatusOK, response: `"alice"`},
+		{name: "no credentials", path: "/basic", code: http.StatusUnauthorized, authenticate: `Basic realm="books"`},
+		{name: "wrong password", path: "/basic", username: "alice", password: "guess", code: http.StatusUnauthorized, authenticate: `Basic realm="books"`},
+		{name: "anonymous allowed by permissions", path: "/anonymous", code: http.StatusOK, response: `""`},
+		{name: "authenticated with permissions", path: "/anonymous", username: "alice", password: "secret", code: http.StatusOK, response: `"alice"`},
+		{name: "no challenge", path: "/unchallenged", code: http.StatusForbidden},
+		{name: "no challenge with permissions", path: "/unchallenged-permissions", code: http.StatusForbidden},
+		{name: "unconfigured default classes", path: "/defaults", username: "alice", password: "secret", code: http.StatusUnauthorized, authenticate: `Basic realm="api"`},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, test.path, nil)
+		if test.username != "" {
+			r.SetBasicAuth(test.username, test.password)
+		}
+		eng.ServeHTTP(w, r)
+		if w.Code != test.code {
+			t.Errorf("%s: got status %d, want %d", test.name, w.Code, test.code)
+			continue
+		}
+		if test.response != "" && w.Body.String() != test.response {
+			t.Errorf("%s: got response %s, want %s", test.name, w.Body.String(), test.response)
+		}
+		if got := w.Header().Get("WWW-Authenticate"); got != test.authenticate {
+			t.Errorf("%s: got WWW-Authenticate %q, want %q", test.name, got, test.authenticate)
+		}
+	}
+}
+
+// testTokenStore a TokenStore of the tokens keyed by their key.
+type testTokenStore map[string]Token
+
+func (s testTokenStore) Lookup(_ context.Context, key string) (*Token, error) {
+	token, ok := s[key]
+	if !ok {
+		return nil, errors.New("token not found")
+	}
+	return &token, nil
+}
+
+func TestTokenAuthentication(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	provider := testUserProvider{1: {id: 1, username: "alice"}}
+	store := testTokenStore{"abc123": {ID: 1, Key: "abc123", UserID: 1}, "orphan": {ID: 2, Key: "orphan", UserID: 2}}
+	eng := gin.New()
+	Path(eng, "/token", NewHandler[bookSerializer](
+		WithAuthentication[bookSerializer](NewTokenAuthentication(provider, store)),
+	).Get(func(ctx *gin.Context, _ *bookSerializer) (int, any, error) {
+		return http.StatusOK, GetRequest(ctx).User.Username() + " " + GetRequest(ctx).Auth, nil
+	}), "")
+
+	tests := []struct {
+		name          string
+		authorization string
+		code          int
+		response      string
+	}{
+		{name: "valid", authorization: "Token abc123", code: http.StatusOK, response: `"alice abc123"`},
+		{name: "keyword is case insensitive", authorization: "token abc123", code: http.StatusOK, response: `"alice abc123"`},
+		{name: "surrounding spaces", authorization: "Token  abc123 ", code: http.StatusOK, response: `"alice abc123"`},
+		{name: "no header", code: http.StatusUnauthorized},
+		{name: "wrong keyword", authorization: "Bearer abc123", code: http.StatusUnauthorized},
+		{name: "missing token", authorization: "Token", code: http.StatusUnauthorized},
+		{name: "blank token", authorization: "Token   ", code: http.StatusUnauthorized},
+		{name: "token with spaces", authorization: "Token abc 123", code: http.StatusUnauthorized},
+		{name: "unknown token", authorization: "Token def456", code: http.StatusUnauthorized},
+		{name: "unknown user", authorization: "Token orphan", code: http.StatusUnauthorized},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/token", nil)
+		if test.authorization != "" {
+			r.Header.Set("Authorization", test.authorization)
+		}
+		eng.ServeHTTP(w, r)
+		if w.Code != test.code {
+			t.Errorf("%s: got status %d, want %d", test.name, w.Code, test.code)
+			continue
+		}
+		if test.code == http.StatusUnauthorized {
+			if got := w.Header().Get("WWW-Authenticate"); got != "Token" {
+				t.Errorf("%s: got WWW-Authenticate %q, want %q", test.name, got, "Token")
+			}
+			continue
+		}
+		if w.Body.String() != test.response {
+			t.Errorf("%s: got response %s, want %s", test.name, w.Body.String(), test.response)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package authtoken
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	django "github.com/malijoe/djanGo-unchained"
+	"github.com/malijoe/djanGo-unchained/db"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/schema"
+)
+
+var (
+	ErrorTokenNotFound = errors.New("token not found")
+)
+
+// TokenDTO the database representation of a django.Token. db.Repository identifies rows by an `id` column,
+// which the `authtoken_token` table of Django REST framework, keyed by `key`, lacks: tokens have their own table.
+type TokenDTO struct {
+	bun.BaseModel `bun:"table:authtoken_apitoken"`
+
+	ID      uint      `bun:"id,pk,autoincrement"`
+	Key     string    `bun:"key,unique,notnull"`
+	UserID  uint      `bun:"user_id,notnull"`
+	Created time.Time `bun:"created,notnull"`
+}
+
+func (TokenDTO) FromEntity(t django.Token) any {
+	return TokenDTO{
+		ID:      t.ID,
+		Key:     t.Key,
+		UserID:  t.UserID,
+		Created: t.Created,
+	}
+}
+
+func (d TokenDTO) ToEntity() django.Token {
+	return django.Token{
+		ID:      d.ID,
+		Key:     d.Key,
+		UserID:  d.UserID,
+		Created: d.Created,
+	}
+}
+
+// NewRepository creates a token repository on the `authtoken_apitoken` table.
+func NewRepository(conn *sql.DB, dialect schema.Dialect) db.Repository[django.Token] {
+	return db.NewRepository[TokenDTO, django.Token](conn, dialect)
+}
+
+var _ django.TokenStore = (*Store)(nil)
+
+// Store a django.TokenStore that issues, rotates and revokes tokens kept in a repository.
+type Store struct {
+	repo db.Repository[django.Token]
+}
+
+func NewStore(repo db.Repository[django.Token]) *Store {
+	return &Store{
+		repo: repo,
+	}
+}
+
+// GenerateKey returns a random 40 character hexadecimal key.
+func GenerateKey() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func (s *Store) Lookup(ctx context.Context, key string) (*django.Token, error) {
+	return lookup(ctx, s.repo, key)
+}
+
+// Create issues a new token to the user.
+func (s *Store) Create(ctx context.Context, userID uint) (*django.Token, error) {
+	return create(ctx, s.repo, userID)
+}
+
+// Rotate replaces the token with the given key by a new token issued to the same user.
+func (s *Store) Rotate(ctx context.Context, key string) (*django.Token, error) {
+	tx, err := s.repo.WithTx()
+	if err != nil {
+		return nil, err
+	}
+	token, err := lookup(ctx, tx, key)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	if err = tx.Delete(ctx, token.ID); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	rotated, err := create(ctx, tx, token.UserID)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return rotated, nil
+}
+
+// Revoke deletes the token with the given key.
+func (s *Store) Revoke(ctx context.Context, key string) error {
+	token, err := lookup(ctx, s.repo, key)
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, token.ID)
+}
+
+func lookup(ctx context.Context, repo db.BaseRepository[django.Token], key string) (*django.Token, error) {
+	tokens, err := repo.Find(ctx, db.Equal("key", key))
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, ErrorTokenNotFound
+	}
+	return &tokens[0], nil
+}
+
+func create(ctx context.Context, repo db.BaseRepository[django.Token], userID uint) (*django.Token, error) {
+	key, err := GenerateKey()
+	if err != nil {
+		return nil, fmt.Errorf("could not generate token key: %w", err)
+	}
+	return repo.Save(ctx, django.Token{
+		Key:     key,
+		UserID:  userID,
+		Created: time.Now(),
+	})
+}
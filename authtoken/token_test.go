@@ -0,0 +1,101 @@
+package authtoken
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	django "github.com/malijoe/djanGo-unchained"
+	"github.com/malijoe/djanGo-unchained/db"
+)
+
+// newTokenRepository returns a repository keeping tokens in memory, found by key.
+func newTokenRepository(tokens ...django.Token) (*db.MockRepository[django.Token], map[uint]django.Token) {
+	stored := make(map[uint]django.Token)
+	var lastID uint
+	for _, token := range tokens {
+		stored[token.ID] = token
+		if token.ID > lastID {
+			lastID = token.ID
+		}
+	}
+	repo := db.NewMockRepository[django.Token](
+		db.WithSaveFn(func(token *django.Token) (*django.Token, error) {
+			lastID++
+			token.ID = lastID
+			stored[token.ID] = *token
+			return token, nil
+		}),
+		db.WithFindFn(func(query db.Specification) ([]django.Token, error) {
+			var found []django.Token
+			for _, token := range stored {
+				if token.Key == query.Values()[0] {
+					found = append(found, token)
+				}
+			}
+			return found, nil
+		}),
+		db.WithDeleteFn[django.Token](func(id uint) error {
+			delete(stored, id)
+			return nil
+		}),
+	)
+	return repo, stored
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	repo, stored := newTokenRepository(django.Token{ID: 1, Key: "alice", UserID: 1})
+	store := NewStore(repo)
+
+	token, err := store.Lookup(ctx, "alice")
+	if err != nil || token.UserID != 1 {
+		t.Fatalf("Lookup got %+v, %v, want the token of user 1", token, err)
+	}
+	if _, err = store.Lookup(ctx, "missing"); !errors.Is(err, ErrorTokenNotFound) {
+		t.Errorf("Lookup of an unknown key got error %v, want %v", err, ErrorTokenNotFound)
+	}
+
+	created, err := store.Create(ctx, 2)
+	if err != nil {
+		t.Fatalf("Create returned error %v", err)
+	}
+	if len(created.Key) != 40 || created.UserID != 2 || created.Created.IsZero() {
+		t.Errorf("Create got %+v, want a 40 character key issued to user 2", created)
+	}
+	if _, ok := stored[created.ID]; !ok {
+		t.Errorf("Create did not save the token")
+	}
+
+	rotated, err := store.Rotate(ctx, "alice")
+	if err != nil {
+		t.Fatalf("Rotate returned error %v", err)
+	}
+	if rotated.Key == "alice" || rotated.UserID != 1 {
+		t.Errorf("Rotate got %+v, want a new key issued to user 1", rotated)
+	}
+	if _, ok := stored[1]; ok {
+		t.Errorf("Rotate did not delete the rotated token")
+	}
+	if !repo.WithTxInvoked || !repo.CommitInvoked || repo.RollbackInvoked {
+		t.Errorf("Rotate got transaction begun %t, committed %t, rolled back %t, want true, true, false", repo.WithTxInvoked, repo.CommitInvoked, repo.RollbackInvoked)
+	}
+
+	repo.CommitInvoked = false
+	if _, err = store.Rotate(ctx, "alice"); !errors.Is(err, ErrorTokenNotFound) {
+		t.Errorf("Rotate of a rotated key got error %v, want %v", err, ErrorTokenNotFound)
+	}
+	if !repo.RollbackInvoked || repo.CommitInvoked {
+		t.Errorf("failed Rotate got committed %t, rolled back %t, want false, true", repo.CommitInvoked, repo.RollbackInvoked)
+	}
+
+	if err = store.Revoke(ctx, rotated.Key); err != nil {
+		t.Fatalf("Revoke returned error %v", err)
+	}
+	if _, err = store.Lookup(ctx, rotated.Key); !errors.Is(err, ErrorTokenNotFound) {
+		t.Errorf("Lookup of a revoked key got error %v, want %v", err, ErrorTokenNotFound)
+	}
+	if err = store.Revoke(ctx, rotated.Key); !errors.Is(err, ErrorTokenNotFound) {
+		t.Errorf("Revoke of a revoked key got error %v, want %v", err, ErrorTokenNotFound)
+	}
+}
@@ -0,0 +1,257 @@
+package django
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrorNoReverseMatch = errors.New("no reverse match")
+)
+
+// Converter converts between a typed value and a path segment.
+type Converter interface {
+	// Regex returns the regular expression a path segment must match.
+	Regex() string
+	// ToURL converts a value into a path segment.
+	ToURL(value any) (string, error)
+}
+
+// IntConverter matches zero or any positive integer.
+type IntConverter struct{}
+
+func (IntConverter) Regex() string {
+	return "[0-9]+"
+}
+
+func (IntConverter) ToURL(value any) (string, error) {
+	return fmt.Sprint(value), nil
+}
+
+// StrConverter matches any non-empty string, excluding the path separator.
+type StrConverter struct{}
+
+func (StrConverter) Regex() string {
+	return "[^/]+"
+}
+
+func (StrConverter) ToURL(value any) (string, error) {
+	return fmt.Sprint(value), nil
+}
+
+// SlugConverter matches any slug string consisting of ASCII letters or numbers, plus the hyphen and underscore characters.
+type SlugConverter struct{}
+
+func (SlugConverter) Regex() string {
+	return "[-a-zA-Z0-9_]+"
+}
+
+func (SlugConverter) ToURL(value any) (string, error) {
+	return fmt.Sprint(value), nil
+}
+
+// UUIDConverter matches a formatted, lowercase UUID.
+type UUIDConverter struct{}
+
+func (UUIDConverter) Regex() string {
+	return "[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}"
+}
+
+func (UUIDConverter) ToURL(value any) (string, error) {
+	return fmt.Sprint(value), nil
+}
+
+// PathConverter matches any non-empty string, including the path separator.
+// it may only be used as the last segment of a path.
+type PathConverter struct{}
+
+func (PathConverter) Regex() string {
+	return ".+"
+}
+
+func (PathConverter) ToURL(value any) (string, error) {
+	return fmt.Sprint(value), nil
+}
+
+var (
+	convertersMu sync.RWMutex
+	converters   = map[string]Converter{
+		"int":  IntConverter{},
+		"str":  StrConverter{},
+		"slug": SlugConverter{},
+		"uuid": UUIDConverter{},
+		"path": PathConverter{},
+	}
+)
+
+// RegisterConverter makes a converter available to path patterns as <name:param>.
+func RegisterConverter(name string, converter Converter) {
+	convertersMu.Lock()
+	defer convertersMu.Unlock()
+	converters[name] = converter
+}
+
+func getConverter(name string) (Converter, bool) {
+	convertersMu.RLock()
+	defer convertersMu.RUnlock()
+	c, ok := converters[name]
+	return c, ok
+}
+
+var segmentRegex = regexp.MustCompile(`^<(?:(\w+):)?(\w+)>$`)
+
+type segment struct {
+	literal   string
+	param     string
+	catchAll  bool
+	converter Converter
+	regex     *regexp.Regexp
+}
+
+// pattern a parsed path such as `/articles/<int:year>/<slug:title>`.
+// gin style `:param` and `*param` segments are accepted and are not validated.
+type pattern struct {
+	raw      string
+	segments []segment
+}
+
+func parsePattern(raw string) *pattern {
+	p := &pattern{raw: raw}
+	parts := strings.Split(strings.Trim(raw, "/"), "/")
+	for i, part := range parts {
+		if part == "" {
+			continue
+		}
+		var s segment
+		switch {
+		case segmentRegex.MatchString(part):
+			match := segmentRegex.FindStringSubmatch(part)
+			name := match[1]
+			if name == "" {
+				name = "str"
+			}
+			converter, ok := getConverter(name)
+			if !ok {
+				panic(fmt.Sprintf("path %q: unknown converter %q", raw, name))
+			}
+			_, isPath := converter.(PathConverter)
+			if isPath && i != len(parts)-1 {
+				panic(fmt.Sprintf("path %q: the path converter may only be used in the last segment", raw))
+			}
+			s = segment{
+				param:     match[2],
+				catchAll:  isPath,
+				converter: converter,
+				regex:     regexp.MustCompile(fmt.Sprintf("^(?:%s)$", converter.Regex())),
+			}
+		case strings.HasPrefix(part, ":"):
+			s = segment{param: part[1:]}
+		case strings.HasPrefix(part, "*"):
+			s = segment{param: part[1:], catchAll: true}
+		case strings.ContainsAny(part, "<>"):
+			panic(fmt.Sprintf("path %q: converters must span a whole segment", raw))
+		default:
+			s = segment{literal: part}
+		}
+		p.segments = append(p.segments, s)
+	}
+	return p
+}
+
+// route returns the pattern in the syntax understood by gin.
+func (p *pattern) route() string {
+	var b strings.Builder
+	for _, s := range p.segments {
+		b.WriteString("/")
+		switch {
+		case s.param == "":
+			b.WriteString(s.literal)
+		case s.catchAll:
+			b.WriteString("*" + s.param)
+		default:
+			b.WriteString(":" + s.param)
+		}
+	}
+	if b.Len() == 0 {
+		return "/"
+	}
+	if p.trailingSlash() {
+		b.WriteString("/")
+	}
+	return b.String()
+}
+
+func (p *pattern) trailingSlash() bool {
+	return strings.HasSuffix(p.raw, "/") && len(p.segments) > 0 && !p.segments[len(p.segments)-1].catchAll
+}
+
+// validate checks the values of the path parameters against the converters of the pattern.
+// values of catch-all parameters are stripped of the leading separator gin includes.
+func (p *pattern) validate(params map[string]string) bool {
+	for _, s := range p.segments {
+		if s.param == "" {
+			continue
+		}
+		value, ok := params[s.param]
+		if !ok {
+			continue
+		}
+		if s.catchAll {
+			value = strings.TrimPrefix(value, "/")
+			params[s.param] = value
+		}
+		if s.regex != nil && !s.regex.MatchString(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// reverse builds a path from the pattern, converting each parameter value with its converter.
+// converted values are escaped, except for the slashes separating the segments of `path` values.
+func (p *pattern) reverse(params map[string]any) (string, error) {
+	var b strings.Builder
+	for _, s := range p.segments {
+		b.WriteString("/")
+		if s.param == "" {
+			b.WriteString(s.literal)
+			continue
+		}
+		value, ok := params[s.param]
+		if !ok {
+			return "", fmt.Errorf("%w: missing value for parameter %q of %q", ErrorNoReverseMatch, s.param, p.raw)
+		}
+		converter := s.converter
+		if converter == nil {
+			converter = StrConverter{}
+		}
+		str, err := converter.ToURL(value)
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", ErrorNoReverseMatch, err)
+		}
+		if s.regex != nil && !s.regex.MatchString(str) {
+			return "", fmt.Errorf("%w: %q is not a valid value for parameter %q of %q", ErrorNoReverseMatch, str, s.param, p.raw)
+		}
+		b.WriteString(escapePath(str))
+	}
+	if b.Len() == 0 {
+		return "/", nil
+	}
+	if p.trailingSlash() {
+		b.WriteString("/")
+	}
+	return b.String(), nil
+}
+
+// escapePath escapes each `/` separated segment of value.
+func escapePath(value string) string {
+	segments := strings.Split(value, "/")
+	for i := range segments {
+		segments[i] = url.PathEscape(segments[i])
+	}
+	return strings.Join(segments, "/")
+}
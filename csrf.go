@@ -0,0 +1,137 @@
+package django
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slices"
+)
+
+var (
+	// CSRF_TRUSTED_ORIGINS origins, besides the origin of the request itself, that unsafe requests may come from,
+	// e.g. "https://admin.example.com" or "https://*.example.com".
+	CSRF_TRUSTED_ORIGINS = []string{}
+	// CSRF_COOKIE_NAME the cookie EnsureCSRFCookie exposes the CSRF token in.
+	CSRF_COOKIE_NAME = "csrftoken"
+	// CSRF_HEADER_NAME the header the CSRF token is exposed in, and expected in, on unsafe requests.
+	CSRF_HEADER_NAME = "X-CSRFToken"
+	// CSRF_FORM_FIELD the form field the CSRF token is accepted from when the header is not set.
+	CSRF_FORM_FIELD = "csrfmiddlewaretoken"
+)
+
+const csrfSessionKey = "csrfToken"
+
+// CSRFExempt disables CSRF validation of session authenticated requests to the handler.
+func CSRFExempt[R Serializer]() Opt[R] {
+	return func(h *Handler[R]) {
+		h.csrfExempt = true
+	}
+}
+
+// CSRFToken returns the CSRF token of the request's session, creating it on first use.
+func CSRFToken(ctx *gin.Context) (string, error) {
+	session := GetRequest(ctx).Session()
+	if session == nil {
+		return "", ErrorNoSession
+	}
+	if token, ok := session.Get(csrfSessionKey).(string); ok && token != "" {
+		return token, nil
+	}
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(b)
+	session.Put(csrfSessionKey, token)
+	return token, nil
+}
+
+// EnsureCSRFCookie exposes the CSRF token of the session in the CSRF_COOKIE_NAME cookie
+// and the CSRF_HEADER_NAME header of every response.
+func EnsureCSRFCookie() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		token, err := CSRFToken(ctx)
+		if err != nil {
+			_ = ctx.Error(err)
+			abort(ctx, ErrInternal())
+			return
+		}
+		ctx.SetSameSite(http.SameSiteLaxMode)
+		ctx.SetCookie(CSRF_COOKIE_NAME, token, 0, "/", "", ctx.Request.TLS != nil, false)
+		ctx.Header(CSRF_HEADER_NAME, token)
+		ctx.Next()
+	}
+}
+
+// enforceCSRF checks that an unsafe request comes from a trusted origin and carries the CSRF token of its session.
+func enforceCSRF(request *Request) *APIError {
+	if request.csrfExempt || slices.Contains(SAFE_METHODS, request.Method) {
+		return nil
+	}
+	if origin := request.Header.Get("Origin"); origin != "" {
+		if !trustedOrigin(request, origin) {
+			return csrfFailed("origin checking failed - " + origin + " does not match any trusted origins")
+		}
+	} else if request.TLS != nil {
+		referer, err := url.Parse(request.Referer())
+		if err != nil || referer.Host == "" {
+			return csrfFailed("referer checking failed - no referer")
+		}
+		if !trustedOrigin(request, referer.Scheme+"://"+referer.Host) {
+			return csrfFailed("referer checking failed - " + referer.Host + " does not match any trusted origins")
+		}
+	}
+
+	expected, _ := request.Session().Get(csrfSessionKey).(string)
+	if expected == "" {
+		return csrfFailed("CSRF token missing")
+	}
+	token := request.Header.Get(CSRF_HEADER_NAME)
+	if token == "" {
+		var apiErr *APIError
+		if token, apiErr = request.postFormValue(CSRF_FORM_FIELD); apiErr != nil {
+			return apiErr
+		}
+	}
+	if token == "" {
+		return csrfFailed("CSRF token missing")
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+		return csrfFailed("CSRF token incorrect")
+	}
+	return nil
+}
+
+func csrfFailed(reason string) *APIError {
+	return NewAPIError(http.StatusForbidden, "CSRF failed: "+reason, "csrf_failed")
+}
+
+// trustedOrigin reports whether origin is the origin of the request itself or one of CSRF_TRUSTED_ORIGINS.
+func trustedOrigin(request *Request, origin string) bool {
+	scheme := "http"
+	if request.TLS != nil {
+		scheme = "https"
+	}
+	if origin == scheme+"://"+request.Host {
+		return true
+	}
+	for _, trusted := range CSRF_TRUSTED_ORIGINS {
+		if origin == trusted {
+			return true
+		}
+		trustedScheme, trustedHost, ok := strings.Cut(trusted, "://")
+		if !ok || !strings.HasPrefix(trustedHost, "*.") {
+			continue
+		}
+		originScheme, originHost, ok := strings.Cut(origin, "://")
+		if ok && originScheme == trustedScheme && strings.HasSuffix(originHost, trustedHost[1:]) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package django
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alexedwards/scs/v2"
+	"github.com/gin-gonic/gin"
+)
+
+func TestTrustedOrigin(t *testing.T) {
+	defer func(origins []string) { CSRF_TRUSTED_ORIGINS = origins }(CSRF_TRUSTED_ORIGINS)
+	CSRF_TRUSTED_ORIGINS = []string{"https://admin.example.com", "https://*.example.org"}
+
+	tests := []struct {
+		origin string
+		tls    bool
+		want   bool
+	}{
+		{origin: "http://api.example.com", want: true},
+		{origin: "https://api.example.com", tls: true, want: true},
+		{origin: "https://api.example.com", want: false},
+		{origin: "https://admin.example.com", want: true},
+		{origin: "http://admin.example.com", want: false},
+		{origin: "https://shop.example.org", want: true},
+		{origin: "http://shop.example.org", want: false},
+		{origin: "https://example.org.evil.com", want: false},
+	}
+	for _, test := range tests {
+		request := &Request{Request: &http.Request{Host: "api.example.com"}}
+		if test.tls {
+			request.TLS = &tls.ConnectionState{}
+		}
+		if got := trustedOrigin(request, test.origin); got != test.want {
+			t.Errorf("%s (tls %t): got %t, want %t", test.origin, test.tls, got, test.want)
+		}
+	}
+}
+
+func TestCSRFFormField(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	provider := testUserProvider{1: {id: 1, username: "alice", password: "secret"}}
+	manager := scs.New()
+	eng := gin.New()
+	eng.Use(SessionMiddleware(manager))
+	Path(eng, "/login", LoginHandler(provider), "")
+	Path(eng, "/csrf", NewHandler[bookSerializer]().Get(func(ctx *gin.Context, _ *bookSerializer) (int, any, error) {
+		token, err := CSRFToken(ctx)
+		return http.StatusOK, token, err
+	}), "")
+	Path(eng, "/uploads", NewHandler[uploadSerializer](
+		WithAuthentication[uploadSerializer](NewSessionAuthentication(provider, manager)),
+		WithParsers[uploadSerializer](MultiPartParser{MaxMemory: 512, MaxDisk: 512}),
+	).Post(func(ctx *gin.Context, s *uploadSerializer) (int, any, error) {
+		return http.StatusOK, s.Title, nil
+	}), "")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"username":"alice","password":"secret"}`))
+	r.Header.Set("Content-Type", MIMEJSON)
+	eng.ServeHTTP(w, r)
+	session := w.Result().Cookies()[0]
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodGet, "/csrf", nil)
+	r.AddCookie(session)
+	eng.ServeHTTP(w, r)
+	var token string
+	_ = json.Unmarshal(w.Body.Bytes(), &token)
+
+	multipartBody := func(formToken string, size int) (string, string) {
+		var body bytes.Buffer
+		w := multipart.NewWriter(&body)
+		if formToken != "" {
+			_ = w.WriteField(CSRF_FORM_FIELD, formToken)
+		}
+		_ = w.WriteField("title", "dune")
+		f, _ := w.CreateFormFile("document", "dune.pdf")
+		_, _ = f.Write(bytes.Repeat([]byte("x"), size))
+		_ = w.Close()
+		return body.String(), w.FormDataContentType()
+	}
+
+	tests := []struct {
+		name                   string
+		headerToken, formToken string
+		size                   int
+		code                   int
+	}{
+		{name: "header token", headerToken: token, size: 8, code: http.StatusOK},
+		{name: "form token", formToken: token, size: 8, code: http.StatusOK},
+		{name: "wrong form token", formToken: "wrong", size: 8, code: http.StatusForbidden},
+		{name: "missing token", size: 8, code: http.StatusForbidden},
+		{name: "header token with a large upload", headerToken: token, size: 2048, code: http.StatusRequestEntityTooLarge},
+		{name: "form token with a large upload", formToken: token, size: 2048, code: http.StatusRequestEntityTooLarge},
+	}
+	for _, test := range tests {
+		body, contentType := multipartBody(test.formToken, test.size)
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/uploads", strings.NewReader(body))
+		r.Header.Set("Content-Type", contentType)
+		if test.headerToken != "" {
+			r.Header.Set(CSRF_HEADER_NAME, test.headerToken)
+		}
+		r.AddCookie(session)
+		eng.ServeHTTP(w, r)
+		if w.Code != test.code {
+			t.Errorf("%s: got status %d, want %d (%s)", test.name, w.Code, test.code, w.Body.String())
+		}
+	}
+}
@@ -31,6 +31,12 @@ func WithUpdateFn[T any](updateFn func(*T) (*T, error)) Opt[T] {
 	}
 }
 
+func WithUpdateColumnsFn[T any](updateColumnsFn func(*T, []string) (*T, error)) Opt[T] {
+	return func(r *MockRepository[T]) {
+		r.updateColumnsFn = updateColumnsFn
+	}
+}
+
 func WithDeleteFn[T any](deleteFn func(uint) error) Opt[T] {
 	return func(r *MockRepository[T]) {
 		r.deleteFn = deleteFn
@@ -50,6 +56,9 @@ type MockRepository[T any] struct {
 	updateFn      func(t *T) (*T, error)
 	UpdateInvoked bool
 
+	updateColumnsFn      func(t *T, columns []string) (*T, error)
+	UpdateColumnsInvoked bool
+
 	deleteFn      func(id uint) error
 	DeleteInvoked bool
 
@@ -104,7 +113,7 @@ func (r *MockRepository[T]) Get(_ context.Context, id uint) (*T, error) {
 func (r *MockRepository[T]) Find(_ context.Context, query Specification) ([]T, error) {
 	r.FindInvoked = true
 	if r.findFn != nil {
-		return r.findFn(nil)
+		return r.findFn(query)
 	}
 	return nil, nil
 }
@@ -117,6 +126,14 @@ func (r *MockRepository[T]) Update(_ context.Context, t T) (*T, error) {
 	return &t, nil
 }
 
+func (r *MockRepository[T]) UpdateColumns(_ context.Context, t T, columns ...string) (*T, error) {
+	r.UpdateColumnsInvoked = true
+	if r.updateColumnsFn != nil {
+		return r.updateColumnsFn(&t, columns)
+	}
+	return &t, nil
+}
+
 func (r *MockRepository[T]) Delete(_ context.Context, id uint) error {
 	r.DeleteInvoked = true
 	if r.deleteFn != nil {
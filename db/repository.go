@@ -27,6 +27,14 @@ type BaseRepository[T any] interface {
 	Delete(ctx context.Context, id uint) error
 }
 
+// ColumnUpdater is implemented by repositories able to update only some columns of an entity,
+// as the repositories created by NewRepository are.
+type ColumnUpdater[T any] interface {
+	// UpdateColumns updates the given columns of the row of t, leaving its other columns untouched.
+	// every column is updated, zero values included, when none is given.
+	UpdateColumns(ctx context.Context, t T, columns ...string) (*T, error)
+}
+
 type Repository[T any] interface {
 	WithTx(tx ...*sql.Tx) (TxRepository[T], error)
 	BaseRepository[T]
@@ -50,7 +58,7 @@ type baseRepository[D DTO[E], E any] struct {
 	db conn
 }
 
-func newBaseRepository[D DTO[E], E any](conn conn) BaseRepository[E] {
+func newBaseRepository[D DTO[E], E any](conn conn) *baseRepository[D, E] {
 	return &baseRepository[D, E]{
 		db: conn,
 	}
@@ -115,6 +123,21 @@ func (r *baseRepository[D, E]) Update(ctx context.Context, e E) (*E, error) {
 	return &entity, nil
 }
 
+func (r *baseRepository[D, E]) UpdateColumns(ctx context.Context, e E, columns ...string) (*E, error) {
+	var dto D
+	dto = dto.FromEntity(e).(D)
+
+	stmt := r.db.NewUpdate().Model(&dto).Column(columns...).WherePK()
+
+	_, err := stmt.Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entity := dto.ToEntity()
+	return &entity, nil
+}
+
 func (r *baseRepository[D, E]) Delete(ctx context.Context, id uint) error {
 	var dto D
 	stmt := r.db.NewDelete().Model(&dto).Where("id = ?", id)
@@ -127,7 +150,7 @@ func (r *baseRepository[D, E]) Delete(ctx context.Context, id uint) error {
 
 type repository[D DTO[E], E any] struct {
 	db *bun.DB
-	BaseRepository[E]
+	*baseRepository[D, E]
 }
 
 func NewRepository[D DTO[E], E any](conn *sql.DB, dialect schema.Dialect) Repository[E] {
@@ -140,7 +163,7 @@ func NewRepository[D DTO[E], E any](conn *sql.DB, dialect schema.Dialect) Reposi
 		))
 	r := repository[D, E]{
 		db:             db,
-		BaseRepository: newBaseRepository[D, E](db),
+		baseRepository: newBaseRepository[D, E](db),
 	}
 	return &r
 }
@@ -151,7 +174,7 @@ func (r *repository[D, E]) WithTx(tx ...*sql.Tx) (TxRepository[E], error) {
 
 type txRepository[D DTO[E], E any] struct {
 	tx bun.Tx
-	BaseRepository[E]
+	*baseRepository[D, E]
 }
 
 func newTxRepository[D DTO[E], E any](db *bun.DB, tx ...*sql.Tx) (TxRepository[E], error) {
@@ -166,7 +189,7 @@ func newTxRepository[D DTO[E], E any](db *bun.DB, tx ...*sql.Tx) (TxRepository[E
 	}
 	return &txRepository[D, E]{
 		tx:             ttx,
-		BaseRepository: newBaseRepository[D, E](ttx),
+		baseRepository: newBaseRepository[D, E](ttx),
 	}, nil
 }
 
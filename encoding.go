@@ -0,0 +1,122 @@
+package django
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+var (
+	// DEFAULT_MAX_BODY_SIZE the size request bodies are limited to, as sent, by handlers configured without
+	// WithMaxBodySize. zero means no limit.
+	DEFAULT_MAX_BODY_SIZE int64 = 0
+	// DEFAULT_MAX_DECODED_BODY_SIZE the size compressed request bodies are limited to once decoded, by handlers
+	// configured without WithMaxDecodedBodySize, protecting against decompression bombs. zero means no limit.
+	DEFAULT_MAX_DECODED_BODY_SIZE int64 = 100 << 20
+)
+
+// limitBody limits the body of the request to maxSize bytes and transparently decodes its `Content-Encoding`,
+// limiting the decoded body to maxDecodedSize bytes. limits of zero or less are disabled.
+func limitBody(request *Request, maxSize, maxDecodedSize int64) *APIError {
+	if !hasBody(request.Request) {
+		return nil
+	}
+	if maxSize > 0 {
+		if request.ContentLength > maxSize {
+			return requestTooLarge()
+		}
+		request.limitBody(maxSize)
+	}
+
+	encodings := strings.Split(request.Header.Get("Content-Encoding"), ",")
+	decoded := false
+	// encodings are listed in the order they were applied.
+	for i := len(encodings) - 1; i >= 0; i-- {
+		encoding := strings.ToLower(strings.TrimSpace(encodings[i]))
+		if encoding == "" || encoding == "identity" {
+			continue
+		}
+		reader, err := decoder(encoding, request.Body)
+		if err != nil {
+			return err
+		}
+		request.Body = struct {
+			io.Reader
+			io.Closer
+		}{reader, request.Body}
+		decoded = true
+	}
+	if !decoded {
+		return nil
+	}
+	request.Header.Del("Content-Encoding")
+	request.ContentLength = -1
+	if maxDecodedSize > 0 {
+		request.limitBody(maxDecodedSize)
+	}
+	return nil
+}
+
+// decoder returns a reader decoding the encoding from r. errors in the encoded stream surface when reading.
+func decoder(encoding string, r io.Reader) (io.Reader, *APIError) {
+	switch encoding {
+	case "gzip", "x-gzip":
+		return &lazyReader{open: func() (io.Reader, error) {
+			return gzip.NewReader(r)
+		}}, nil
+	case "deflate":
+		// "deflate" is meant to be zlib wrapped, but some clients send raw deflate streams.
+		buffered := bufio.NewReader(r)
+		return &lazyReader{open: func() (io.Reader, error) {
+			header, err := buffered.Peek(2)
+			if err == nil && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+				return zlib.NewReader(buffered)
+			}
+			return flate.NewReader(buffered), nil
+		}}, nil
+	case "br":
+		return brotli.NewReader(r), nil
+	}
+	return nil, NewAPIError(http.StatusUnsupportedMediaType, fmt.Sprintf("unsupported content encoding %q", encoding), "unsupported_content_encoding")
+}
+
+// lazyReader opens its reader on the first read, as gzip and zlib readers read their header when created.
+type lazyReader struct {
+	open   func() (io.Reader, error)
+	reader io.Reader
+	err    error
+}
+
+func (r *lazyReader) Read(p []byte) (int, error) {
+	if r.reader == nil && r.err == nil {
+		r.reader, r.err = r.open()
+	}
+	if r.err != nil {
+		return 0, r.err
+	}
+	return r.reader.Read(p)
+}
+
+// limitBody makes the body of the request fail with ErrorBodyTooLarge past size bytes.
+func (r *Request) limitBody(size int64) {
+	body := &limitedBody{ReadCloser: r.Body, remaining: size}
+	r.Body = body
+	r.bodyLimits = append(r.bodyLimits, body)
+}
+
+// bodyTooLarge returns true if reading the body of the request exceeded one of its limits.
+func (r *Request) bodyTooLarge() bool {
+	for _, body := range r.bodyLimits {
+		if body.exceeded {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package django
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+func compress(t *testing.T, encoding string, data string) string {
+	var (
+		buf bytes.Buffer
+		w   io.WriteCloser
+	)
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "deflate":
+		w = zlib.NewWriter(&buf)
+	case "raw-deflate":
+		w, _ = flate.NewWriter(&buf, flate.DefaultCompression)
+	case "br":
+		w = brotli.NewWriter(&buf)
+	}
+	if _, err := io.WriteString(w, data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestBodyLimits(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	echo := func(ctx *gin.Context, b *bookSerializer) (int, any, error) {
+		return http.StatusOK, b, nil
+	}
+	eng := gin.New()
+	Path(eng, "/books", NewHandler[bookSerializer]().Post(echo), "")
+	Path(eng, "/small", NewHandler[bookSerializer](WithMaxBodySize[bookSerializer](32), WithMaxDecodedBodySize[bookSerializer](64)).Post(echo), "")
+
+	book := `{"title":"dune"}`
+	bomb := `{"title":"` + strings.Repeat("a", 1024) + `"}`
+	tests := []struct {
+		path, encoding, body string
+		unknownLength        bool
+		code                 int
+	}{
+		{path: "/books", encoding: "gzip", body: compress(t, "gzip", book), code: http.StatusOK},
+		{path: "/books", encoding: "deflate", body: compress(t, "deflate", book), code: http.StatusOK},
+		{path: "/books", encoding: "deflate", body: compress(t, "raw-deflate", book), code: http.StatusOK},
+		{path: "/books", encoding: "br", body: compress(t, "br", book), code: http.StatusOK},
+		{path: "/books", encoding: "identity, gzip", body: compress(t, "gzip", book), code: http.StatusOK},
+		{path: "/books", encoding: "gzip", body: book, code: http.StatusBadRequest},
+		{path: "/books", encoding: "compress", body: book, code: http.StatusUnsupportedMediaType},
+		{path: "/small", body: book, code: http.StatusOK},
+		{path: "/small", body: bomb, code: http.StatusRequestEntityTooLarge},
+		{path: "/small", body: bomb, unknownLength: true, code: http.StatusRequestEntityTooLarge},
+		{path: "/small", encoding: "gzip", body: compress(t, "gzip", bomb), code: http.StatusRequestEntityTooLarge},
+	}
+	for i, test := range tests {
+		w := httptest.NewRecorder()
+		var body io.Reader = strings.NewReader(test.body)
+		if test.unknownLength {
+			body = io.MultiReader(body)
+		}
+		r := httptest.NewRequest(http.MethodPost, test.path, body)
+		r.Header.Set("Content-Type", MIMEJSON)
+		if test.encoding != "" {
+			r.Header.Set("Content-Encoding", test.encoding)
+		}
+		eng.ServeHTTP(w, r)
+		if w.Code != test.code {
+			t.Errorf("%d %s %q: got %d, want %d (%s)", i, test.path, test.encoding, w.Code, test.code, w.Body.String())
+		}
+		if test.code == http.StatusOK && w.Body.String() != `{"id":0,"title":"dune"}` {
+			t.Errorf("%d %s %q: got %s", i, test.path, test.encoding, w.Body.String())
+		}
+	}
+}
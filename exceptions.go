@@ -0,0 +1,43 @@
+package django
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIError an error rendered as the body of a response, e.g. `{"detail": "not found"}`.
+type APIError struct {
+	Status int    `json:"-"`
+	Detail string `json:"detail"`
+	Code   string `json:"code,omitempty"`
+}
+
+func NewAPIError(status int, detail string, code ...string) *APIError {
+	err := APIError{
+		Status: status,
+		Detail: detail,
+	}
+	if len(code) > 0 {
+		err.Code = code[0]
+	}
+	return &err
+}
+
+func (e *APIError) Error() string {
+	return e.Detail
+}
+
+func ErrNotFound() *APIError {
+	return NewAPIError(http.StatusNotFound, "not found", "not_found")
+}
+
+func ErrInternal() *APIError {
+	return NewAPIError(http.StatusInternalServerError, "a server error occurred", "error")
+}
+
+// abort stops the handler chain and responds with the error.
+func abort(ctx *gin.Context, err *APIError) {
+	ctx.Abort()
+	respond(ctx, err.Status, err)
+}
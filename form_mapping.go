@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"mime/multipart"
 	"reflect"
 	"strconv"
 	"strings"
@@ -36,6 +37,12 @@ func mapURI(ptr any, m map[string][]string) error {
 	return mapFormByTag(ptr, m, "uri")
 }
 
+// mapQuery binds the query to the fields tagged with `query`. unlike the other sources, fields without
+// the tag are never bound by their name: the client controls every parameter of the query.
+func mapQuery(ptr any, query map[string][]string) error {
+	return mappingByPtr(ptr, taggedSource{setter: formSource(query), tag: "query"}, "query")
+}
+
 func mapForm(ptr any, form map[string][]string) error {
 	return mapFormByTag(ptr, form, "form")
 }
@@ -77,6 +84,45 @@ func (f formSource) TrySet(value reflect.Value, field reflect.StructField, tagVa
 	return setByForm(value, field, f, tagValue, opt)
 }
 
+// taggedSource sets only the fields naming their key in tag.
+type taggedSource struct {
+	setter
+	tag string
+}
+
+func (s taggedSource) TrySet(value reflect.Value, field reflect.StructField, tagValue string, opt setOptions) (isSet bool, err error) {
+	if name, _ := head(field.Tag.Get(s.tag), ","); name == "" {
+		return false, nil
+	}
+	return s.setter.TrySet(value, field, tagValue, opt)
+}
+
+// mapMultipart binds the values of the form like mapForm, and its files to
+// `*multipart.FileHeader` and `[]*multipart.FileHeader` fields.
+func mapMultipart(ptr any, form *multipart.Form) error {
+	return mappingByPtr(ptr, (*multipartSource)(form), "form")
+}
+
+type multipartSource multipart.Form
+
+func (m *multipartSource) TrySet(value reflect.Value, field reflect.StructField, tagValue string, opt setOptions) (isSet bool, err error) {
+	if files := m.File[tagValue]; len(files) > 0 {
+		switch value.Kind() {
+		case reflect.Struct:
+			if _, ok := value.Interface().(multipart.FileHeader); ok {
+				value.Set(reflect.ValueOf(*files[0]))
+				return true, nil
+			}
+		case reflect.Slice:
+			if _, ok := value.Interface().([]*multipart.FileHeader); ok {
+				value.Set(reflect.ValueOf(files))
+				return true, nil
+			}
+		}
+	}
+	return setByForm(value, field, m.Value, tagValue, opt)
+}
+
 var emptyField = reflect.StructField{}
 
 func mappingByPtr(ptr any, setter setter, tag string) error {
@@ -239,6 +285,8 @@ func setWithProperType(val string, value reflect.Value, field reflect.StructFiel
 		return setFloatField(val, 32, value)
 	case reflect.Float64:
 		return setFloatField(val, 64, value)
+	case reflect.String:
+		value.SetString(val)
 	case reflect.Struct:
 		switch value.Interface().(type) {
 		case time.Time:
@@ -250,7 +298,7 @@ func setWithProperType(val string, value reflect.Value, field reflect.StructFiel
 	default:
 		return errUnsupportedType
 	}
-	//return nil
+	return nil
 }
 
 func setIntField(val string, bitSize int, field reflect.Value) error {
@@ -4,9 +4,13 @@ import (
 	"encoding"
 	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"golang.org/x/exp/slices"
 	"gopkg.in/yaml.v2"
 )
@@ -14,20 +18,88 @@ import (
 type HandleFunc[Request any] func(ctx *gin.Context, serializer *Request) (code int, response any, err error)
 
 func (f HandleFunc[Request]) Wrap() gin.HandlerFunc {
+	return f.wrap(nil, parse)
+}
+
+// wrap binds requests with bind onto the serializer returned by instance, when given, instead of a zero value.
+func (f HandleFunc[Request]) wrap(instance func(ctx *gin.Context) (*Request, *APIError, error), bind func(ctx *gin.Context, ptr any) *APIError) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		var request Request
-		if err := ctx.ShouldBind(&request); err != nil {
-			ctx.JSON(http.StatusBadRequest, err.Error())
+		request := new(Request)
+		if instance != nil {
+			i, apiErr, err := instance(ctx)
+			if err != nil {
+				_ = ctx.Error(err)
+			}
+			if apiErr != nil {
+				abort(ctx, apiErr)
+				return
+			}
+			request = i
+		}
+		if err := bind(ctx, request); err != nil {
+			abort(ctx, err)
 			return
 		}
-		code, response, err := f(ctx, &request)
+		code, response, err := f(ctx, request)
 		if err != nil {
 			ctx.Error(err)
 		}
-		ctx.JSON(code, response)
+		if code == http.StatusUnauthorized {
+			if header := GetRequest(ctx).authenticateHeader(); header != "" {
+				ctx.Header("WWW-Authenticate", header)
+			}
+		}
+		respond(ctx, code, response)
 	}
 }
 
+// parse binds the request to ptr from, in order, its query, its body and the parameters of its path.
+// a field bound by several sources keeps the value of the last one: path parameters take precedence over
+// the body, which takes precedence over the query. the query is bound to fields by their `query` tags,
+// the path by their `uri` tags and the body by its parser, chosen by its `Content-Type`.
+func parse(ctx *gin.Context, ptr any) *APIError {
+	if err := (QueryParser{}).ParseUri(ctx.Request.URL.Query()).Decode(ptr); err != nil {
+		return NewAPIError(http.StatusBadRequest, err.Error(), "invalid")
+	}
+	if hasBody(ctx.Request) && !GetRequest(ctx).streamBody {
+		parser, apiErr := selectParser(GetRequest(ctx).Parsers, ctx.Request)
+		if apiErr != nil {
+			return apiErr
+		}
+		decoder, err := parser.Parse(ctx.Request)
+		if err == nil {
+			err = decoder.Decode(ptr)
+		}
+		if err != nil {
+			_ = ctx.Error(err)
+			return GetRequest(ctx).parseError(err)
+		}
+	}
+	if err := parsePath(ctx, ptr); err != nil {
+		return err
+	}
+	if err := binding.Validator.ValidateStruct(ptr); err != nil {
+		return NewAPIError(http.StatusBadRequest, err.Error(), "invalid")
+	}
+	return nil
+}
+
+// parsePath binds the parameters of the request's path to ptr by their `uri` tags. it is the only binding of
+// handlers taking no input, which neither read the body nor validate the serializer.
+func parsePath(ctx *gin.Context, ptr any) *APIError {
+	if len(ctx.Params) == 0 {
+		return nil
+	}
+	params := make(map[string][]string, len(ctx.Params))
+	for _, p := range ctx.Params {
+		params[p.Key] = []string{p.Value}
+	}
+	if err := (PathParser{}).ParseUri(params).Decode(ptr); err != nil {
+		return NewAPIError(http.StatusBadRequest, err.Error(), "invalid")
+	}
+	return nil
+}
+
 type Opt[R Serializer] func(*Handler[R])
 
 func EnforceContentMatch[R Serializer]() Opt[R] {
@@ -49,10 +121,96 @@ func EnforceContentMatch[R Serializer]() Opt[R] {
 	}
 }
 
+// WithAuthentication authenticates requests with the given classes, tried in order.
+// DEFAULT_AUTHENTICATION_CLASSES are used when no classes are given.
+// requests no class authenticates are answered with 401, 403 when the first class provides no
+// `WWW-Authenticate` header, unless the handler has permission classes,
+// in which case the permission classes decide whether the anonymous request is allowed.
+func WithAuthentication[R Serializer](classes ...AuthenticationClass) Opt[R] {
+	return func(h *Handler[R]) {
+		if len(classes) == 0 {
+			classes = DEFAULT_AUTHENTICATION_CLASSES
+		}
+		h.authenticators = classes
+	}
+}
+
+// WithPermissions checks every permission class against requests after they are authenticated.
+// denied requests are answered with 401 when not authenticated, 403 otherwise or when no authentication
+// class provides a `WWW-Authenticate` header.
+func WithPermissions[R Serializer](classes ...PermissionClass) Opt[R] {
+	return func(h *Handler[R]) {
+		h.permissions = classes
+	}
+}
+
+// WithThrottles limits the rate of requests with every throttle once they are authenticated and permitted.
+// DEFAULT_THROTTLE_CLASSES are used when no throttles are given. throttled requests are answered with 429.
+func WithThrottles[R Serializer](throttles ...Throttle) Opt[R] {
+	return func(h *Handler[R]) {
+		if len(throttles) == 0 {
+			throttles = DEFAULT_THROTTLE_CLASSES
+		}
+		h.throttles = throttles
+	}
+}
+
+// WithRenderers renders responses with the renderer of the given renderers the client accepts,
+// DEFAULT_RENDERER_CLASSES by default. requests accepting none of them are answered with 406.
+func WithRenderers[R Serializer](renderers ...Renderer) Opt[R] {
+	return func(h *Handler[R]) {
+		h.renderers = renderers
+	}
+}
+
+// WithParsers parses request bodies with the first of the parsers handling their `Content-Type`,
+// DEFAULT_PARSER_CLASSES by default. requests no parser handles are answered with 415.
+func WithParsers[R Serializer](parsers ...Parser) Opt[R] {
+	return func(h *Handler[R]) {
+		h.parsers = parsers
+	}
+}
+
+// StreamBody leaves the body of requests unparsed, for the view to stream it with Request.JSONStream.
+func StreamBody[R Serializer]() Opt[R] {
+	return func(h *Handler[R]) {
+		h.streamBody = true
+	}
+}
+
+// WithMaxBodySize answers requests with bodies larger than size bytes, as sent, with 413.
+// DEFAULT_MAX_BODY_SIZE is used when not set, a negative size disables the limit.
+func WithMaxBodySize[R Serializer](size int64) Opt[R] {
+	return func(h *Handler[R]) {
+		h.maxBodySize = size
+	}
+}
+
+// WithMaxDecodedBodySize answers requests with compressed bodies larger than size bytes once decoded with 413.
+// DEFAULT_MAX_DECODED_BODY_SIZE is used when not set, a negative size disables the limit.
+func WithMaxDecodedBodySize[R Serializer](size int64) Opt[R] {
+	return func(h *Handler[R]) {
+		h.maxDecodedBodySize = size
+	}
+}
+
 type Handler[R Serializer] struct {
 	get, post, put, patch, delete HandleFunc[R]
 	contentMustMatch              bool
 	acceptedContent               []string
+	authenticators                []AuthenticationClass
+	permissions                   []PermissionClass
+	throttles                     []Throttle
+	renderers                     []Renderer
+	parsers                       []Parser
+	streamBody                    bool
+	maxBodySize                   int64
+	maxDecodedBodySize            int64
+	csrfExempt                    bool
+	// instance loads the serializer PATCH requests are bound onto, for them to only change the fields they carry.
+	instance func(ctx *gin.Context) (*R, *APIError, error)
+	// noInput the methods whose handlers take no input, only the parameters of the path being bound.
+	noInput []string
 }
 
 func NewHandler[R Serializer](opts ...Opt[R]) *Handler[R] {
@@ -65,15 +223,116 @@ func NewHandler[R Serializer](opts ...Opt[R]) *Handler[R] {
 
 func (h *Handler[R]) contentMiddleware(ctx *gin.Context) {
 	if len(h.acceptedContent) > 0 {
-		content_type := ctx.GetHeader("Content-Type")
-		if !slices.Contains(h.acceptedContent, content_type) {
-			ctx.JSON(http.StatusNotAcceptable, "unaccepted content-type")
+		if mt := mediaType(ctx.Request); !slices.Contains(h.acceptedContent, mt) {
+			abort(ctx, unsupportedMediaType(mt))
+			return
+		}
+	}
+	ctx.Next()
+}
+
+// initial creates the Request of the context from the handler's configuration, negotiates the renderer
+// of the response and limits and decodes the body of the request.
+func (h *Handler[R]) initial(ctx *gin.Context) {
+	defer removeUploads(ctx)
+	request := GetRequest(ctx)
+	request.Authenticators = h.authenticators
+	request.Permissions = h.permissions
+	request.Parsers = h.parsers
+	request.streamBody = h.streamBody
+	request.csrfExempt = h.csrfExempt
+	if err := request.negotiate(h.renderers); err != nil {
+		abort(ctx, err)
+		return
+	}
+	maxBodySize, maxDecodedBodySize := h.maxBodySize, h.maxDecodedBodySize
+	if maxBodySize == 0 {
+		maxBodySize = DEFAULT_MAX_BODY_SIZE
+	}
+	if maxDecodedBodySize == 0 {
+		maxDecodedBodySize = DEFAULT_MAX_DECODED_BODY_SIZE
+	}
+	if err := limitBody(request, maxBodySize, maxDecodedBodySize); err != nil {
+		abort(ctx, err)
+		return
+	}
+	ctx.Next()
+}
+
+// removeUploads deletes the temporary files of the multipart form parsed from the request. net/http only
+// deletes those of the request it created, which middleware such as SessionMiddleware replace.
+func removeUploads(ctx *gin.Context) {
+	if form := ctx.Request.MultipartForm; form != nil {
+		if err := form.RemoveAll(); err != nil {
+			_ = ctx.Error(err)
+		}
+	}
+}
+
+func (h *Handler[R]) authenticationMiddleware(ctx *gin.Context) {
+	request := GetRequest(ctx)
+	if err := request.authenticate(); err != nil {
+		_ = ctx.Error(err)
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			h.reject(ctx, apiErr)
+			return
+		}
+		if len(h.permissions) == 0 {
+			h.reject(ctx, NewAPIError(request.unauthenticatedStatus(), "authentication credentials were not provided or are invalid", "not_authenticated"))
 			return
 		}
 	}
 	ctx.Next()
 }
 
+func (h *Handler[R]) permissionMiddleware(ctx *gin.Context) {
+	if err := GetRequest(ctx).checkPermissions(); err != nil {
+		h.reject(ctx, err)
+		return
+	}
+	ctx.Next()
+}
+
+// throttleMiddleware rejects requests refused by a throttle, telling the client to retry after the longest wait.
+// throttles failing to decide let the request through.
+func (h *Handler[R]) throttleMiddleware(ctx *gin.Context) {
+	request := GetRequest(ctx)
+	var (
+		wait      time.Duration
+		throttled bool
+	)
+	for _, throttle := range h.throttles {
+		allowed, w, err := throttle.AllowRequest(request)
+		if err != nil {
+			_ = ctx.Error(err)
+			continue
+		}
+		if !allowed {
+			throttled = true
+			if w > wait {
+				wait = w
+			}
+		}
+	}
+	if throttled {
+		ctx.Header("Retry-After", strconv.Itoa(retryAfter(wait)))
+		h.reject(ctx, throttledError(wait))
+		return
+	}
+	ctx.Next()
+}
+
+// reject aborts the request with err, challenging the client to authenticate on 401.
+func (h *Handler[R]) reject(ctx *gin.Context, err *APIError) {
+	if err.Status == http.StatusUnauthorized {
+		if header := GetRequest(ctx).authenticateHeader(); header != "" {
+			ctx.Header("WWW-Authenticate", header)
+		}
+	}
+	abort(ctx, err)
+}
+
 func (h *Handler[R]) Get(handle HandleFunc[R]) *Handler[R] {
 	h.get = handle
 	return h
@@ -128,10 +387,20 @@ func (h *Handler[R]) options(ctx *gin.Context) {
 		metadata[method] = fieldMetadata
 	}
 
-	ctx.JSON(http.StatusOK, metadata)
+	respond(ctx, http.StatusOK, metadata)
 }
 
 func (h *Handler[R]) asView(router *gin.RouterGroup) {
+	router.Use(h.initial)
+	if len(h.authenticators) > 0 {
+		router.Use(h.authenticationMiddleware)
+	}
+	if len(h.permissions) > 0 {
+		router.Use(h.permissionMiddleware)
+	}
+	if len(h.throttles) > 0 {
+		router.Use(h.throttleMiddleware)
+	}
 	if h.contentMustMatch {
 		router.Use(h.contentMiddleware)
 	}
@@ -143,8 +412,17 @@ func (h *Handler[R]) asView(router *gin.RouterGroup) {
 		http.MethodDelete: h.delete,
 	}
 	for verb, handler := range handleMap {
-		if handler != nil {
+		if handler == nil {
+			continue
+		}
+		switch {
+		case verb == http.MethodPatch:
+			router.Handle(verb, "", handler.wrap(h.instance, parse))
+		case slices.Contains(h.noInput, verb):
+			router.Handle(verb, "", handler.wrap(nil, parsePath))
+		default:
 			router.Handle(verb, "", handler.Wrap())
 		}
 	}
+	router.OPTIONS("", h.options)
 }
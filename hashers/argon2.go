@@ -0,0 +1,104 @@
+package hashers
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Argon2Hasher hashes passwords with argon2id. hashes have Django's format,
+// `argon2$argon2id$v=19$m=<memory>,t=<time>,p=<threads>$<salt>$<hash>`. argon2i hashes are verified too.
+type Argon2Hasher struct {
+	// Time the number of passes over the memory.
+	Time uint32
+	// Memory the memory used, in KiB.
+	Memory  uint32
+	Threads uint8
+}
+
+// NewArgon2Hasher creates an Argon2Hasher with Django's parameters: 2 passes over 100 MiB with 8 threads.
+func NewArgon2Hasher() *Argon2Hasher {
+	return &Argon2Hasher{
+		Time:    2,
+		Memory:  102400,
+		Threads: 8,
+	}
+}
+
+const argon2KeyLen = 32
+
+type argon2Hash struct {
+	variant      string
+	time, memory uint32
+	threads      uint8
+	salt, hash   []byte
+}
+
+func (h *Argon2Hasher) Algorithm() string {
+	return "argon2"
+}
+
+func (h *Argon2Hasher) Encode(password string) (string, error) {
+	s := []byte(salt(22))
+	hash := argon2.IDKey([]byte(password), s, h.Time, h.Memory, h.Threads, argon2KeyLen)
+	return fmt.Sprintf("%s$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		h.Algorithm(), argon2.Version, h.Memory, h.Time, h.Threads,
+		base64.RawStdEncoding.EncodeToString(s), base64.RawStdEncoding.EncodeToString(hash)), nil
+}
+
+func (h *Argon2Hasher) Verify(password, encoded string) error {
+	decoded, err := h.decode(encoded)
+	if err != nil {
+		return err
+	}
+	var hash []byte
+	keyLen := uint32(len(decoded.hash))
+	if decoded.variant == "argon2i" {
+		hash = argon2.Key([]byte(password), decoded.salt, decoded.time, decoded.memory, decoded.threads, keyLen)
+	} else {
+		hash = argon2.IDKey([]byte(password), decoded.salt, decoded.time, decoded.memory, decoded.threads, keyLen)
+	}
+	if subtle.ConstantTimeCompare(hash, decoded.hash) != 1 {
+		return ErrorPasswordMismatch
+	}
+	return nil
+}
+
+func (h *Argon2Hasher) MustUpdate(encoded string) bool {
+	decoded, err := h.decode(encoded)
+	if err != nil {
+		return false
+	}
+	return decoded.variant != "argon2id" || decoded.time != h.Time || decoded.memory != h.Memory || decoded.threads != h.Threads
+}
+
+func (h *Argon2Hasher) decode(encoded string) (*argon2Hash, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[0] != h.Algorithm() {
+		return nil, ErrorInvalidHash
+	}
+	decoded := argon2Hash{
+		variant: parts[1],
+	}
+	if decoded.variant != "argon2id" && decoded.variant != "argon2i" {
+		return nil, fmt.Errorf("%w: unsupported variant %q", ErrorInvalidHash, decoded.variant)
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, fmt.Errorf("%w: unsupported version %q", ErrorInvalidHash, parts[2])
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &decoded.memory, &decoded.time, &decoded.threads); err != nil {
+		return nil, ErrorInvalidHash
+	}
+	var err error
+	if decoded.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, ErrorInvalidHash
+	}
+	if decoded.hash, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(decoded.hash) == 0 {
+		return nil, ErrorInvalidHash
+	}
+	return &decoded, nil
+}
@@ -0,0 +1,108 @@
+package hashers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// BCryptSHA256Hasher hashes passwords with bcrypt after hashing them with SHA256,
+// lifting bcrypt's 72 bytes limit on passwords. hashes have the format `bcrypt_sha256$<bcrypt hash>`.
+type BCryptSHA256Hasher struct {
+	Cost int
+}
+
+// NewBCryptSHA256Hasher creates a BCryptSHA256Hasher with the given cost, 12 by default.
+func NewBCryptSHA256Hasher(cost ...int) *BCryptSHA256Hasher {
+	h := BCryptSHA256Hasher{
+		Cost: 12,
+	}
+	if len(cost) > 0 {
+		h.Cost = cost[0]
+	}
+	return &h
+}
+
+func (h *BCryptSHA256Hasher) Algorithm() string {
+	return "bcrypt_sha256"
+}
+
+func (h *BCryptSHA256Hasher) Encode(password string) (string, error) {
+	return encodeBCrypt(h.Algorithm(), h.prehash(password), h.Cost)
+}
+
+func (h *BCryptSHA256Hasher) Verify(password, encoded string) error {
+	return verifyBCrypt(h.Algorithm(), h.prehash(password), encoded)
+}
+
+func (h *BCryptSHA256Hasher) MustUpdate(encoded string) bool {
+	return mustUpdateBCrypt(h.Algorithm(), encoded, h.Cost)
+}
+
+func (h *BCryptSHA256Hasher) prehash(password string) []byte {
+	digest := sha256.Sum256([]byte(password))
+	return []byte(hex.EncodeToString(digest[:]))
+}
+
+// BCryptHasher hashes passwords with bcrypt, which only considers their first 72 bytes.
+// hashes have the format `bcrypt$<bcrypt hash>`.
+type BCryptHasher struct {
+	Cost int
+}
+
+// NewBCryptHasher creates a BCryptHasher with the given cost, 12 by default.
+func NewBCryptHasher(cost ...int) *BCryptHasher {
+	h := BCryptHasher{
+		Cost: 12,
+	}
+	if len(cost) > 0 {
+		h.Cost = cost[0]
+	}
+	return &h
+}
+
+func (h *BCryptHasher) Algorithm() string {
+	return "bcrypt"
+}
+
+func (h *BCryptHasher) Encode(password string) (string, error) {
+	return encodeBCrypt(h.Algorithm(), []byte(password), h.Cost)
+}
+
+func (h *BCryptHasher) Verify(password, encoded string) error {
+	return verifyBCrypt(h.Algorithm(), []byte(password), encoded)
+}
+
+func (h *BCryptHasher) MustUpdate(encoded string) bool {
+	return mustUpdateBCrypt(h.Algorithm(), encoded, h.Cost)
+}
+
+func encodeBCrypt(algorithm string, password []byte, cost int) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword(password, cost)
+	if err != nil {
+		return "", err
+	}
+	return algorithm + "$" + string(hash), nil
+}
+
+func verifyBCrypt(algorithm string, password []byte, encoded string) error {
+	if !strings.HasPrefix(encoded, algorithm+"$") {
+		return ErrorInvalidHash
+	}
+	err := bcrypt.CompareHashAndPassword([]byte(encoded[len(algorithm)+1:]), password)
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return ErrorPasswordMismatch
+	}
+	return err
+}
+
+func mustUpdateBCrypt(algorithm, encoded string, cost int) bool {
+	if !strings.HasPrefix(encoded, algorithm+"$") {
+		return false
+	}
+	hashCost, err := bcrypt.Cost([]byte(encoded[len(algorithm)+1:]))
+	return err == nil && hashCost != cost
+}
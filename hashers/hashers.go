@@ -0,0 +1,117 @@
+// Package hashers hashes and checks passwords in the formats used by Django,
+// so that password hashes can be shared with Django services.
+package hashers
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+var (
+	ErrorUnknownHasher    = errors.New("unknown password hashing algorithm")
+	ErrorPasswordMismatch = errors.New("password does not match")
+	ErrorInvalidHash      = errors.New("invalid password hash")
+)
+
+var (
+	// PASSWORD_HASHERS the hashers passwords can be checked with. passwords are hashed with the first one,
+	// hashes made by the others need a rehash.
+	PASSWORD_HASHERS = []Hasher{
+		NewPBKDF2SHA256Hasher(),
+		NewArgon2Hasher(),
+		NewBCryptSHA256Hasher(),
+		NewBCryptHasher(),
+	}
+)
+
+// UNUSABLE_PASSWORD_PREFIX prefixes the hash of users that cannot log in with a password.
+const UNUSABLE_PASSWORD_PREFIX = "!"
+
+type Hasher interface {
+	// Algorithm returns the name the hashes of the hasher are prefixed with, e.g. "pbkdf2_sha256".
+	Algorithm() string
+	// Encode hashes the password with a new random salt.
+	Encode(password string) (string, error)
+	// Verify returns nil if the password matches the encoded hash, ErrorPasswordMismatch otherwise.
+	Verify(password, encoded string) error
+	// MustUpdate returns true if the encoded hash was made with weaker parameters than the hasher's.
+	MustUpdate(encoded string) bool
+}
+
+// MakePassword hashes the password with the preferred hasher.
+func MakePassword(password string) (string, error) {
+	if len(PASSWORD_HASHERS) == 0 {
+		return "", fmt.Errorf("%w: no password hashers", ErrorUnknownHasher)
+	}
+	return PASSWORD_HASHERS[0].Encode(password)
+}
+
+// MakeUnusablePassword returns a hash no password matches.
+func MakeUnusablePassword() string {
+	return UNUSABLE_PASSWORD_PREFIX + salt(40)
+}
+
+// IsPasswordUsable returns false if the hash was made by MakeUnusablePassword.
+func IsPasswordUsable(encoded string) bool {
+	return encoded != "" && !strings.HasPrefix(encoded, UNUSABLE_PASSWORD_PREFIX)
+}
+
+// CheckPassword returns nil if the password matches the encoded hash, whichever hasher made it.
+func CheckPassword(password, encoded string) error {
+	if !IsPasswordUsable(encoded) {
+		return ErrorPasswordMismatch
+	}
+	hasher, err := IdentifyHasher(encoded)
+	if err != nil {
+		return err
+	}
+	return hasher.Verify(password, encoded)
+}
+
+// MustUpdate returns true if the encoded hash should be replaced by a hash of the preferred hasher,
+// either because it was made by another hasher or with weaker parameters.
+// callers rehash the password with MakePassword once it was checked.
+func MustUpdate(encoded string) bool {
+	if !IsPasswordUsable(encoded) || len(PASSWORD_HASHERS) == 0 {
+		return false
+	}
+	hasher, err := IdentifyHasher(encoded)
+	if err != nil {
+		return false
+	}
+	preferred := PASSWORD_HASHERS[0]
+	return hasher.Algorithm() != preferred.Algorithm() || hasher.MustUpdate(encoded)
+}
+
+// IdentifyHasher returns the hasher of PASSWORD_HASHERS that made the encoded hash.
+func IdentifyHasher(encoded string) (Hasher, error) {
+	algorithm, _, ok := strings.Cut(encoded, "$")
+	if !ok {
+		return nil, ErrorUnknownHasher
+	}
+	for _, hasher := range PASSWORD_HASHERS {
+		if hasher.Algorithm() == algorithm {
+			return hasher, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %q", ErrorUnknownHasher, algorithm)
+}
+
+const saltChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// salt returns a random string of n alphanumeric characters, as Django generates salts.
+func salt(n int) string {
+	limit := big.NewInt(int64(len(saltChars)))
+	b := make([]byte, n)
+	for i := range b {
+		r, err := rand.Int(rand.Reader, limit)
+		if err != nil {
+			panic(err)
+		}
+		b[i] = saltChars[r.Int64()]
+	}
+	return string(b)
+}
@@ -0,0 +1,52 @@
+package hashers
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckPassword(t *testing.T) {
+	defer func(hashers []Hasher) { PASSWORD_HASHERS = hashers }(PASSWORD_HASHERS)
+	PASSWORD_HASHERS = []Hasher{
+		NewPBKDF2SHA256Hasher(),
+		&Argon2Hasher{Time: 1, Memory: 1024, Threads: 1},
+		NewBCryptSHA256Hasher(4),
+		NewBCryptHasher(4),
+	}
+
+	// a hash made by Django for "lètmein".
+	django := "pbkdf2_sha256$390000$seasalt$8xBlGd3jVgvJ+92hWPxi5ww0uuAuAnKgC45eudxro7c="
+	if err := CheckPassword("lètmein", django); err != nil {
+		t.Errorf("django hash: got %v, want nil", err)
+	}
+	if MustUpdate(django) {
+		t.Error("django hash: got must update, want up to date")
+	}
+
+	for i, hasher := range PASSWORD_HASHERS {
+		encoded, err := hasher.Encode("lètmein")
+		if err != nil {
+			t.Fatalf("%s: %v", hasher.Algorithm(), err)
+		}
+		if err = CheckPassword("lètmein", encoded); err != nil {
+			t.Errorf("%s: got %v, want nil", hasher.Algorithm(), err)
+		}
+		if err = CheckPassword("letmein", encoded); !errors.Is(err, ErrorPasswordMismatch) {
+			t.Errorf("%s: wrong password got %v, want %v", hasher.Algorithm(), err, ErrorPasswordMismatch)
+		}
+		if got, want := MustUpdate(encoded), i > 0; got != want {
+			t.Errorf("%s: got must update %t, want %t", hasher.Algorithm(), got, want)
+		}
+	}
+
+	weaker, _ := NewPBKDF2SHA256Hasher(1000).Encode("lètmein")
+	if !MustUpdate(weaker) {
+		t.Error("fewer iterations: got up to date, want must update")
+	}
+	if err := CheckPassword("lètmein", "md5$salt$hash"); !errors.Is(err, ErrorUnknownHasher) {
+		t.Errorf("unknown hasher: got %v, want %v", err, ErrorUnknownHasher)
+	}
+	if err := CheckPassword("", MakeUnusablePassword()); !errors.Is(err, ErrorPasswordMismatch) {
+		t.Errorf("unusable password: got %v, want %v", err, ErrorPasswordMismatch)
+	}
+}
@@ -0,0 +1,70 @@
+package hashers
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// PBKDF2SHA256Hasher hashes passwords with PBKDF2-SHA256, Django's default hasher.
+// hashes have the format `pbkdf2_sha256$<iterations>$<salt>$<base64 hash>`.
+type PBKDF2SHA256Hasher struct {
+	Iterations int
+}
+
+// NewPBKDF2SHA256Hasher creates a PBKDF2SHA256Hasher running the given number of iterations, 390000 by default.
+func NewPBKDF2SHA256Hasher(iterations ...int) *PBKDF2SHA256Hasher {
+	h := PBKDF2SHA256Hasher{
+		Iterations: 390000,
+	}
+	if len(iterations) > 0 {
+		h.Iterations = iterations[0]
+	}
+	return &h
+}
+
+func (h *PBKDF2SHA256Hasher) Algorithm() string {
+	return "pbkdf2_sha256"
+}
+
+func (h *PBKDF2SHA256Hasher) Encode(password string) (string, error) {
+	return h.encode(password, salt(22), h.Iterations), nil
+}
+
+func (h *PBKDF2SHA256Hasher) encode(password, salt string, iterations int) string {
+	hash := pbkdf2.Key([]byte(password), []byte(salt), iterations, sha256.Size, sha256.New)
+	return fmt.Sprintf("%s$%d$%s$%s", h.Algorithm(), iterations, salt, base64.StdEncoding.EncodeToString(hash))
+}
+
+func (h *PBKDF2SHA256Hasher) Verify(password, encoded string) error {
+	iterations, salt, err := h.decode(encoded)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare([]byte(h.encode(password, salt, iterations)), []byte(encoded)) != 1 {
+		return ErrorPasswordMismatch
+	}
+	return nil
+}
+
+func (h *PBKDF2SHA256Hasher) MustUpdate(encoded string) bool {
+	iterations, _, err := h.decode(encoded)
+	return err == nil && iterations != h.Iterations
+}
+
+func (h *PBKDF2SHA256Hasher) decode(encoded string) (iterations int, salt string, err error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 4 || parts[0] != h.Algorithm() {
+		return 0, "", ErrorInvalidHash
+	}
+	iterations, err = strconv.Atoi(parts[1])
+	if err != nil || iterations <= 0 {
+		return 0, "", ErrorInvalidHash
+	}
+	return iterations, parts[2], nil
+}
@@ -0,0 +1,483 @@
+package django
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	JWTAlgorithmHS256 = "HS256"
+	JWTAlgorithmRS256 = "RS256"
+	JWTAlgorithmEdDSA = "EdDSA"
+
+	accessTokenType  = "access"
+	refreshTokenType = "refresh"
+)
+
+var (
+	ErrorInvalidToken = errors.New("invalid token")
+	ErrorExpiredToken = errors.New("token has expired")
+)
+
+// JWTKey the key signing and verifying JSON web tokens with one algorithm.
+// keys built from a public key can only verify tokens.
+type JWTKey struct {
+	algorithm string
+	signing   any
+	verifying any
+}
+
+// NewHMACKey creates an HS256 key from a shared secret.
+func NewHMACKey(secret []byte) *JWTKey {
+	return &JWTKey{
+		algorithm: JWTAlgorithmHS256,
+		signing:   secret,
+		verifying: secret,
+	}
+}
+
+// NewRSAKey creates an RS256 key. a nil private key creates a key that can only verify tokens.
+func NewRSAKey(private *rsa.PrivateKey, public *rsa.PublicKey) *JWTKey {
+	k := JWTKey{
+		algorithm: JWTAlgorithmRS256,
+		verifying: public,
+	}
+	if private != nil {
+		k.signing = private
+		k.verifying = &private.PublicKey
+	}
+	return &k
+}
+
+// NewEd25519Key creates an EdDSA key. a nil private key creates a key that can only verify tokens.
+func NewEd25519Key(private ed25519.PrivateKey, public ed25519.PublicKey) *JWTKey {
+	k := JWTKey{
+		algorithm: JWTAlgorithmEdDSA,
+		verifying: public,
+	}
+	if private != nil {
+		k.signing = private
+		k.verifying = private.Public()
+	}
+	return &k
+}
+
+// LoadJWTKey reads an RSA or Ed25519 key from a PEM file. private keys are expected in PKCS #1 or
+// PKCS #8 form, public keys in PKIX or PKCS #1 form. the algorithm is chosen from the type of the key.
+func LoadJWTKey(path string) (*JWTKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data found", path)
+	}
+
+	var key any
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%s: unsupported PEM block type %q", path, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return NewRSAKey(k, nil), nil
+	case *rsa.PublicKey:
+		return NewRSAKey(nil, k), nil
+	case ed25519.PrivateKey:
+		return NewEd25519Key(k, nil), nil
+	case ed25519.PublicKey:
+		return NewEd25519Key(nil, k), nil
+	}
+	return nil, fmt.Errorf("%s: unsupported key type %T", path, key)
+}
+
+func (k *JWTKey) Algorithm() string {
+	return k.algorithm
+}
+
+func (k *JWTKey) sign(payload []byte) ([]byte, error) {
+	if k.signing == nil {
+		return nil, fmt.Errorf("%s key can only verify tokens", k.algorithm)
+	}
+	switch k.algorithm {
+	case JWTAlgorithmHS256:
+		mac := hmac.New(sha256.New, k.signing.([]byte))
+		mac.Write(payload)
+		return mac.Sum(nil), nil
+	case JWTAlgorithmRS256:
+		digest := sha256.Sum256(payload)
+		return rsa.SignPKCS1v15(rand.Reader, k.signing.(*rsa.PrivateKey), crypto.SHA256, digest[:])
+	case JWTAlgorithmEdDSA:
+		return ed25519.Sign(k.signing.(ed25519.PrivateKey), payload), nil
+	}
+	return nil, fmt.Errorf("unsupported algorithm %s", k.algorithm)
+}
+
+func (k *JWTKey) verify(payload, signature []byte) bool {
+	switch k.algorithm {
+	case JWTAlgorithmHS256:
+		mac := hmac.New(sha256.New, k.verifying.([]byte))
+		mac.Write(payload)
+		return hmac.Equal(signature, mac.Sum(nil))
+	case JWTAlgorithmRS256:
+		digest := sha256.Sum256(payload)
+		return rsa.VerifyPKCS1v15(k.verifying.(*rsa.PublicKey), crypto.SHA256, digest[:], signature) == nil
+	case JWTAlgorithmEdDSA:
+		return ed25519.Verify(k.verifying.(ed25519.PublicKey), payload, signature)
+	}
+	return false
+}
+
+// Audience the `aud` claim, encoded as a string when it holds a single value.
+type Audience []string
+
+func (a Audience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+	return json.Marshal([]string(a))
+}
+
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+	*a = multiple
+	return nil
+}
+
+// JWTClaims the claims of a JSON web token. Extra holds every claim of a parsed token, including the registered ones.
+type JWTClaims struct {
+	Subject   string         `json:"sub,omitempty"`
+	Issuer    string         `json:"iss,omitempty"`
+	Audience  Audience       `json:"aud,omitempty"`
+	ExpiresAt int64          `json:"exp,omitempty"`
+	NotBefore int64          `json:"nbf,omitempty"`
+	IssuedAt  int64          `json:"iat,omitempty"`
+	ID        string         `json:"jti,omitempty"`
+	TokenType string         `json:"token_type,omitempty"`
+	Extra     map[string]any `json:"-"`
+}
+
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ,omitempty"`
+}
+
+var jwtEncoding = base64.RawURLEncoding
+
+// SignJWT encodes the claims as a JSON web token signed with key.
+func SignJWT(key *JWTKey, claims JWTClaims) (string, error) {
+	header, err := json.Marshal(jwtHeader{Algorithm: key.algorithm, Type: "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	unsigned := jwtEncoding.EncodeToString(header) + "." + jwtEncoding.EncodeToString(payload)
+	signature, err := key.sign([]byte(unsigned))
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + jwtEncoding.EncodeToString(signature), nil
+}
+
+// ParseJWT verifies the signature of the token with key and returns its claims.
+// the token must be signed with the algorithm of the key; time based claims are not checked.
+func ParseJWT(key *JWTKey, token string) (*JWTClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrorInvalidToken)
+	}
+	rawHeader, err := jwtEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed header", ErrorInvalidToken)
+	}
+	var header jwtHeader
+	if err = json.Unmarshal(rawHeader, &header); err != nil {
+		return nil, fmt.Errorf("%w: malformed header", ErrorInvalidToken)
+	}
+	if header.Algorithm != key.algorithm {
+		return nil, fmt.Errorf("%w: unexpected algorithm %q", ErrorInvalidToken, header.Algorithm)
+	}
+	signature, err := jwtEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", ErrorInvalidToken)
+	}
+	if !key.verify([]byte(parts[0]+"."+parts[1]), signature) {
+		return nil, fmt.Errorf("%w: signature verification failed", ErrorInvalidToken)
+	}
+
+	payload, err := jwtEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed payload", ErrorInvalidToken)
+	}
+	var claims JWTClaims
+	if err = json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("%w: malformed payload", ErrorInvalidToken)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	if err = decoder.Decode(&claims.Extra); err != nil {
+		return nil, fmt.Errorf("%w: malformed payload", ErrorInvalidToken)
+	}
+	return &claims, nil
+}
+
+type JWTOpt func(*JWTAuthentication)
+
+// WithJWTIssuer sets the `iss` claim of issued tokens, and requires it of verified tokens.
+func WithJWTIssuer(issuer string) JWTOpt {
+	return func(a *JWTAuthentication) {
+		a.issuer = issuer
+	}
+}
+
+// WithJWTAudience sets the `aud` claim of issued tokens, and requires verified tokens to be intended for it.
+func WithJWTAudience(audience string) JWTOpt {
+	return func(a *JWTAuthentication) {
+		a.audience = audience
+	}
+}
+
+// WithJWTLeeway tolerates clock skew when checking the `exp` and `nbf` claims.
+func WithJWTLeeway(leeway time.Duration) JWTOpt {
+	return func(a *JWTAuthentication) {
+		a.leeway = leeway
+	}
+}
+
+// WithJWTLifetimes sets how long issued access and refresh tokens are valid; 5 minutes and a day by default.
+func WithJWTLifetimes(access, refresh time.Duration) JWTOpt {
+	return func(a *JWTAuthentication) {
+		a.accessLifetime = access
+		a.refreshLifetime = refresh
+	}
+}
+
+// JWTAuthentication authenticates requests carrying an `Authorization: Bearer <token>` header.
+// the subject of the token is the id of the user, resolved through the UserProvider.
+type JWTAuthentication struct {
+	BaseAuthentication
+	provider        UserProvider
+	key             *JWTKey
+	issuer          string
+	audience        string
+	leeway          time.Duration
+	accessLifetime  time.Duration
+	refreshLifetime time.Duration
+	now             func() time.Time
+}
+
+func NewJWTAuthentication(provider UserProvider, key *JWTKey, opts ...JWTOpt) *JWTAuthentication {
+	auth := JWTAuthentication{
+		provider:        provider,
+		key:             key,
+		accessLifetime:  5 * time.Minute,
+		refreshLifetime: 24 * time.Hour,
+		now:             time.Now,
+	}
+	for _, opt := range opts {
+		opt(&auth)
+	}
+	return &auth
+}
+
+func (a *JWTAuthentication) Authenticate(request *Request) error {
+	if a.provider == nil || a.key == nil {
+		return fmt.Errorf("%w: %v", ErrorCouldNotAuthenticate, ErrorNotConfigured)
+	}
+	keyword, token, _ := strings.Cut(request.Header.Get("Authorization"), " ")
+	if !strings.EqualFold(keyword, "Bearer") || token == "" {
+		return fmt.Errorf("%w: missing or mal-formed authorization header", ErrorCouldNotAuthenticate)
+	}
+	claims, err := a.verify(token, accessTokenType)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrorCouldNotAuthenticate, err)
+	}
+	u, err := a.user(claims)
+	if err != nil {
+		return err
+	}
+	request.User = u
+	request.Auth = token
+	request.Claims = claims
+	return nil
+}
+
+func (a *JWTAuthentication) AuthenticateHeader() string {
+	return `Bearer realm="api"`
+}
+
+// verify parses the token and checks its time based claims, issuer, audience and type.
+func (a *JWTAuthentication) verify(token, tokenType string) (*JWTClaims, error) {
+	claims, err := ParseJWT(a.key, token)
+	if err != nil {
+		return nil, err
+	}
+	now := a.now()
+	if claims.ExpiresAt == 0 {
+		return nil, fmt.Errorf("%w: missing exp claim", ErrorInvalidToken)
+	}
+	if now.Add(-a.leeway).After(time.Unix(claims.ExpiresAt, 0)) {
+		return nil, ErrorExpiredToken
+	}
+	if claims.NotBefore != 0 && now.Add(a.leeway).Before(time.Unix(claims.NotBefore, 0)) {
+		return nil, fmt.Errorf("%w: token is not valid yet", ErrorInvalidToken)
+	}
+	if a.issuer != "" && claims.Issuer != a.issuer {
+		return nil, fmt.Errorf("%w: unexpected issuer %q", ErrorInvalidToken, claims.Issuer)
+	}
+	if a.audience != "" && !slices.Contains(claims.Audience, a.audience) {
+		return nil, fmt.Errorf("%w: token is not intended for %q", ErrorInvalidToken, a.audience)
+	}
+	if claims.TokenType != tokenType {
+		return nil, fmt.Errorf("%w: expected a token of type %q", ErrorInvalidToken, tokenType)
+	}
+	return claims, nil
+}
+
+func (a *JWTAuthentication) user(claims *JWTClaims) (User, error) {
+	id, err := strconv.ParseUint(claims.Subject, 10, 0)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid subject", ErrorInvalidToken)
+	}
+	return a.provider.GetUser(uint(id))
+}
+
+// issue signs a token of the given type for the user.
+func (a *JWTAuthentication) issue(u User, tokenType string, lifetime time.Duration) (string, error) {
+	id, ok := userID(u)
+	if !ok {
+		return "", ErrorNoUserID
+	}
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", err
+	}
+	now := a.now()
+	claims := JWTClaims{
+		Subject:   strconv.FormatUint(uint64(id), 10),
+		Issuer:    a.issuer,
+		ExpiresAt: now.Add(lifetime).Unix(),
+		IssuedAt:  now.Unix(),
+		ID:        hex.EncodeToString(jti),
+		TokenType: tokenType,
+	}
+	if a.audience != "" {
+		claims.Audience = Audience{a.audience}
+	}
+	return SignJWT(a.key, claims)
+}
+
+// TokenObtainSerializer the credentials exchanged for a token pair.
+type TokenObtainSerializer struct {
+	Username string `json:"username" form:"username"`
+	Password string `json:"password" form:"password"`
+}
+
+func (TokenObtainSerializer) Metadata() []Field {
+	return []Field{
+		{Name: "username", Required: true},
+		{Name: "password", Required: true, WriteOnly: true},
+	}
+}
+
+// TokenRefreshSerializer the refresh token exchanged for a new access token.
+type TokenRefreshSerializer struct {
+	Refresh string `json:"refresh" form:"refresh"`
+}
+
+func (TokenRefreshSerializer) Metadata() []Field {
+	return []Field{
+		{Name: "refresh", Required: true, WriteOnly: true},
+	}
+}
+
+type TokenPair struct {
+	Access  string `json:"access"`
+	Refresh string `json:"refresh,omitempty"`
+}
+
+// ObtainTokenHandler a handler exchanging a username and password for an access and refresh token on POST.
+func (a *JWTAuthentication) ObtainTokenHandler(opts ...Opt[TokenObtainSerializer]) *Handler[TokenObtainSerializer] {
+	return NewHandler[TokenObtainSerializer](opts...).Post(func(ctx *gin.Context, credentials *TokenObtainSerializer) (int, any, error) {
+		denied := NewAPIError(http.StatusUnauthorized, "no active account found with the given credentials", "no_active_account")
+		u, err := a.provider.FindUser(credentials.Username)
+		if err != nil {
+			return denied.Status, denied, err
+		}
+		if err = u.ValidatePassword(credentials.Password); err != nil {
+			return denied.Status, denied, err
+		}
+		var pair TokenPair
+		if pair.Access, err = a.issue(u, accessTokenType, a.accessLifetime); err != nil {
+			return http.StatusInternalServerError, ErrInternal(), err
+		}
+		if pair.Refresh, err = a.issue(u, refreshTokenType, a.refreshLifetime); err != nil {
+			return http.StatusInternalServerError, ErrInternal(), err
+		}
+		return http.StatusOK, pair, nil
+	})
+}
+
+// RefreshTokenHandler a handler exchanging a refresh token for a new access token on POST.
+func (a *JWTAuthentication) RefreshTokenHandler(opts ...Opt[TokenRefreshSerializer]) *Handler[TokenRefreshSerializer] {
+	return NewHandler[TokenRefreshSerializer](opts...).Post(func(ctx *gin.Context, refresh *TokenRefreshSerializer) (int, any, error) {
+		denied := NewAPIError(http.StatusUnauthorized, "token is invalid or expired", "token_not_valid")
+		claims, err := a.verify(refresh.Refresh, refreshTokenType)
+		if err != nil {
+			return denied.Status, denied, err
+		}
+		u, err := a.user(claims)
+		if err != nil {
+			return denied.Status, denied, err
+		}
+		var pair TokenPair
+		if pair.Access, err = a.issue(u, accessTokenType, a.accessLifetime); err != nil {
+			return http.StatusInternalServerError, ErrInternal(), err
+		}
+		return http.StatusOK, pair, nil
+	})
+}
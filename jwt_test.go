@@ -0,0 +1,133 @@
+package django
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testUserProvider map[uint]testUser
+
+func (p testUserProvider) GetUser(id uint) (User, error) {
+	u, ok := p[id]
+	if !ok {
+		return nil, errors.New("user not found")
+	}
+	return u, nil
+}
+
+func (p testUserProvider) FindUser(username string) (User, error) {
+	for _, u := range p {
+		if u.username == username {
+			return u, nil
+		}
+	}
+	return nil, errors.New("user not found")
+}
+
+func TestJWTKeys(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(edKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemPath := filepath.Join(t.TempDir(), "ed25519.pem")
+	if err = os.WriteFile(pemPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadJWTKey(pemPath)
+	if err != nil {
+		t.Fatalf("LoadJWTKey returned error %v", err)
+	}
+
+	keys := []*JWTKey{
+		NewHMACKey([]byte("secret")),
+		NewRSAKey(rsaKey, nil),
+		loaded,
+	}
+	claims := JWTClaims{Subject: "1", ExpiresAt: time.Now().Add(time.Minute).Unix()}
+	for _, key := range keys {
+		token, err := SignJWT(key, claims)
+		if err != nil {
+			t.Errorf("%s: SignJWT returned error %v", key.Algorithm(), err)
+			continue
+		}
+		parsed, err := ParseJWT(key, token)
+		if err != nil {
+			t.Errorf("%s: ParseJWT returned error %v", key.Algorithm(), err)
+			continue
+		}
+		if parsed.Subject != claims.Subject || parsed.ExpiresAt != claims.ExpiresAt {
+			t.Errorf("%s: ParseJWT got claims %+v, want %+v", key.Algorithm(), parsed, claims)
+		}
+		if _, err = ParseJWT(NewHMACKey([]byte("other")), token); !errors.Is(err, ErrorInvalidToken) {
+			t.Errorf("%s: ParseJWT with another key got error %v, want %v", key.Algorithm(), err, ErrorInvalidToken)
+		}
+	}
+
+	if _, err = SignJWT(NewRSAKey(nil, &rsaKey.PublicKey), claims); err == nil {
+		t.Error("SignJWT with a public key did not return an error")
+	}
+}
+
+func TestJWTAuthentication(t *testing.T) {
+	provider := testUserProvider{1: {id: 1, username: "alice", password: "secret"}}
+	now := time.Now()
+	auth := NewJWTAuthentication(provider, NewHMACKey([]byte("secret")), WithJWTIssuer("api"), WithJWTAudience("mobile"))
+	auth.now = func() time.Time { return now }
+
+	access, err := auth.issue(provider[1], accessTokenType, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	refresh, err := auth.issue(provider[1], refreshTokenType, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := SignJWT(auth.key, JWTClaims{Subject: "1", Issuer: "other", Audience: Audience{"mobile"}, ExpiresAt: now.Add(time.Minute).Unix(), TokenType: accessTokenType})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		header string
+		after  time.Duration
+		want   bool
+	}{
+		{name: "valid", header: "Bearer " + access, want: true},
+		{name: "expired", header: "Bearer " + access, after: 2 * time.Minute},
+		{name: "refresh token", header: "Bearer " + refresh},
+		{name: "wrong issuer", header: "Bearer " + other},
+		{name: "wrong keyword", header: "Token " + access},
+		{name: "missing", header: ""},
+	}
+	for _, test := range tests {
+		auth.now = func() time.Time { return now.Add(test.after) }
+		request := &Request{Request: &http.Request{Header: http.Header{}}}
+		request.Header.Set("Authorization", test.header)
+		err := auth.Authenticate(request)
+		if got := err == nil; got != test.want {
+			t.Errorf("%s: got authenticated %t, want %t (%v)", test.name, got, test.want, err)
+			continue
+		}
+		if test.want && (request.User.(IdentifiedUser).ID() != 1 || request.Claims == nil || request.Claims.Issuer != "api") {
+			t.Errorf("%s: got user %v and claims %+v", test.name, request.User, request.Claims)
+		}
+	}
+}
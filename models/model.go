@@ -59,3 +59,34 @@ func (m *dataModel[T, R]) Find(ctx context.Context, query db.Specification) ([]T
 func (m *dataModel[T, R]) Delete(ctx context.Context, id uint) error {
 	return m.repo.Delete(ctx, id)
 }
+
+// modelRepository adapts the data models of T to a db.BaseRepository.
+type modelRepository[T Model[T]] struct{}
+
+// AsRepository returns a db.BaseRepository backed by the data models of T.
+func AsRepository[T Model[T]]() db.BaseRepository[T] {
+	return modelRepository[T]{}
+}
+
+func (modelRepository[T]) Save(ctx context.Context, t T) (*T, error) {
+	return t.Objects().Create(ctx)
+}
+
+func (modelRepository[T]) Get(ctx context.Context, id uint) (*T, error) {
+	var t T
+	return t.Objects().Get(ctx, id)
+}
+
+func (modelRepository[T]) Find(ctx context.Context, query db.Specification) ([]T, error) {
+	var t T
+	return t.Objects().Find(ctx, query)
+}
+
+func (modelRepository[T]) Update(ctx context.Context, t T) (*T, error) {
+	return t.Objects().Update(ctx)
+}
+
+func (modelRepository[T]) Delete(ctx context.Context, id uint) error {
+	var t T
+	return t.Objects().Delete(ctx, id)
+}
@@ -2,11 +2,25 @@ package django
 
 import (
 	"bytes"
+	"encoding"
 	"encoding/json"
 	"encoding/xml"
 	"errors"
+	"fmt"
 	"io"
+	"mime"
+	"mime/multipart"
 	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/vmihailenco/msgpack/v5"
+	"golang.org/x/exp/slices"
+	"google.golang.org/protobuf/proto"
+	"gopkg.in/yaml.v2"
 )
 
 const (
@@ -28,6 +42,20 @@ var (
 	ErrorNoBody = errors.New("invalid request: no body")
 )
 
+var (
+	// DEFAULT_PARSER_CLASSES the parsers used by handlers configured without parsers.
+	DEFAULT_PARSER_CLASSES = []Parser{
+		JSONParser{},
+		XMLParser{},
+		FormParser{},
+		MultiPartParser{},
+		YAMLParser{},
+		TOMLParser{},
+		MsgPackParser{},
+		ProtobufParser{},
+	}
+)
+
 type Decoder func(any) error
 
 func (d Decoder) Decode(i any) error {
@@ -49,6 +77,37 @@ type UriParser interface {
 	ParseUri(map[string][]string) Decoder
 }
 
+var (
+	_ UriParser = QueryParser{}
+	_ UriParser = PathParser{}
+)
+
+// QueryParser binds query parameters to fields by their `query` tags. fields without the tag are not bound.
+type QueryParser struct{}
+
+func (QueryParser) MediaTypes() []string {
+	return nil
+}
+
+func (QueryParser) ParseUri(query map[string][]string) Decoder {
+	return func(i any) error {
+		return mapQuery(i, query)
+	}
+}
+
+// PathParser binds path parameters to fields by their `uri` tags.
+type PathParser struct{}
+
+func (PathParser) MediaTypes() []string {
+	return nil
+}
+
+func (PathParser) ParseUri(params map[string][]string) Decoder {
+	return func(i any) error {
+		return mapURI(i, params)
+	}
+}
+
 type JSONParser struct{}
 
 func (JSONParser) Parse(request *http.Request) (Decoder, error) {
@@ -73,6 +132,101 @@ func getJSONDecoder(r io.Reader) Decoder {
 	}
 }
 
+// Stream returns a stream over the elements of the top-level JSON array in the body of the request,
+// for bodies too large to be decoded at once.
+func (JSONParser) Stream(request *http.Request) (*JSONStream, error) {
+	if request == nil || request.Body == nil {
+		return nil, ErrorNoBody
+	}
+	return NewJSONStream(request.Body), nil
+}
+
+// ElementError reports an element of a JSONStream that could not be decoded. the stream continues with
+// the next element.
+type ElementError struct {
+	Index int
+	Err   error
+}
+
+func (e *ElementError) Error() string {
+	return fmt.Sprintf("element %d: %v", e.Index, e.Err)
+}
+
+func (e *ElementError) Unwrap() error {
+	return e.Err
+}
+
+// JSONStream decodes the elements of a top-level JSON array one at a time.
+type JSONStream struct {
+	decoder *json.Decoder
+	index   int
+	started bool
+	// err the error the stream stopped on, io.EOF once the array is exhausted.
+	err error
+}
+
+func NewJSONStream(r io.Reader) *JSONStream {
+	return &JSONStream{
+		decoder: json.NewDecoder(r),
+	}
+}
+
+// Next decodes the next element of the array into v. it returns io.EOF once the array is exhausted,
+// and an *ElementError if the element could not be decoded into v, in which case the stream can go on.
+// any other error means the stream cannot be read further and is returned by every later call.
+func (s *JSONStream) Next(v any) error {
+	if s.err != nil {
+		return s.err
+	}
+	if !s.started {
+		token, err := s.token()
+		if err != nil {
+			return s.stop(err)
+		}
+		if delim, ok := token.(json.Delim); !ok || delim != '[' {
+			return s.stop(ErrorNotJSONArray)
+		}
+		s.started = true
+	}
+	if !s.decoder.More() {
+		// consume the closing bracket, reporting a truncated array.
+		if _, err := s.token(); err != nil {
+			return s.stop(err)
+		}
+		return s.stop(io.EOF)
+	}
+
+	index := s.index
+	s.index++
+	// the element is read whole before being decoded, so that failing to decode it into v
+	// leaves the stream at the next element.
+	var raw json.RawMessage
+	if err := s.decoder.Decode(&raw); err != nil {
+		return s.stop(fmt.Errorf("element %d: %w", index, err))
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return &ElementError{Index: index, Err: err}
+	}
+	return nil
+}
+
+// token reads the next token, the body ending before the array does being unexpected.
+func (s *JSONStream) token() (json.Token, error) {
+	token, err := s.decoder.Token()
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return token, err
+}
+
+func (s *JSONStream) stop(err error) error {
+	s.err = err
+	return err
+}
+
+// XMLParser parses the documents XMLRenderer renders: the root element, whatever its name, holds the value,
+// elements are bound to struct fields by their json names and to map keys, and `list-item` elements to slices.
+// values implementing xml.Unmarshaler are decoded by encoding/xml instead.
 type XMLParser struct{}
 
 func (XMLParser) MediaTypes() []string {
@@ -92,7 +246,460 @@ func (XMLParser) ParseBody(stream []byte) Decoder {
 
 func getXMLDecoder(r io.Reader) Decoder {
 	decoder := xml.NewDecoder(r)
+	return func(i any) error {
+		if _, ok := i.(xml.Unmarshaler); ok {
+			return decoder.Decode(i)
+		}
+		value := reflect.ValueOf(i)
+		if value.Kind() != reflect.Pointer || value.IsNil() {
+			return fmt.Errorf("xml: decode into non-pointer %T", i)
+		}
+		root, err := readXMLRoot(decoder)
+		if err != nil {
+			return err
+		}
+		return decodeXML(root, value.Elem())
+	}
+}
+
+// xmlNode an element of a parsed document.
+type xmlNode struct {
+	name     string
+	text     string
+	children []*xmlNode
+}
+
+// readXMLRoot reads the next root element of the document.
+func readXMLRoot(decoder *xml.Decoder) (*xmlNode, error) {
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return readXMLNode(decoder, start)
+		}
+	}
+}
+
+// readXMLNode reads the element started by start, naming it by its `key` attribute when it is an `item` element.
+func readXMLNode(decoder *xml.Decoder, start xml.StartElement) (*xmlNode, error) {
+	node := &xmlNode{name: start.Name.Local}
+	for _, attr := range start.Attr {
+		if node.name == xmlItem && attr.Name.Local == "key" {
+			node.name = attr.Value
+		}
+	}
+	var text strings.Builder
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			child, err := readXMLNode(decoder, t)
+			if err != nil {
+				return nil, err
+			}
+			node.children = append(node.children, child)
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			node.text = text.String()
+			return node, nil
+		}
+	}
+}
+
+// generic returns the value of the node decoded into an empty interface: a map of its children,
+// a slice of its `list-item` children or its text.
+func (n *xmlNode) generic() any {
+	if len(n.children) == 0 {
+		return n.text
+	}
+	if n.children[0].name == "list-item" {
+		list := make([]any, len(n.children))
+		for i, child := range n.children {
+			list[i] = child.generic()
+		}
+		return list
+	}
+	m := make(map[string]any, len(n.children))
+	for _, child := range n.children {
+		m[child.name] = child.generic()
+	}
+	return m
+}
+
+// decodeXML sets value, which must be settable, from the node.
+func decodeXML(node *xmlNode, value reflect.Value) error {
+	if value.Kind() != reflect.Pointer && value.CanAddr() {
+		if u, ok := value.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return u.UnmarshalText([]byte(node.text))
+		}
+	}
+	text := strings.TrimSpace(node.text)
+	switch value.Kind() {
+	case reflect.Pointer:
+		if len(node.children) == 0 && node.text == "" {
+			value.Set(reflect.Zero(value.Type()))
+			return nil
+		}
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		return decodeXML(node, value.Elem())
+	case reflect.Interface:
+		if value.NumMethod() > 0 {
+			return fmt.Errorf("xml: cannot decode %s into %s", node.name, value.Type())
+		}
+		value.Set(reflect.ValueOf(node.generic()))
+	case reflect.Struct:
+		fields := make(map[string]reflect.Value)
+		_ = jsonFields(value, true, func(name string, _ bool, field reflect.Value) error {
+			fields[name] = field
+			return nil
+		})
+		for _, child := range node.children {
+			if field, ok := fields[child.name]; ok {
+				if err := decodeXML(child, field); err != nil {
+					return err
+				}
+			}
+		}
+	case reflect.Map:
+		if value.IsNil() {
+			value.Set(reflect.MakeMap(value.Type()))
+		}
+		for _, child := range node.children {
+			key := reflect.New(value.Type().Key()).Elem()
+			if err := decodeXML(&xmlNode{name: child.name, text: child.name}, key); err != nil {
+				return err
+			}
+			elem := reflect.New(value.Type().Elem()).Elem()
+			if err := decodeXML(child, elem); err != nil {
+				return err
+			}
+			value.SetMapIndex(key, elem)
+		}
+	case reflect.Slice:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			value.SetBytes([]byte(node.text))
+			return nil
+		}
+		slice := reflect.MakeSlice(value.Type(), 0, len(node.children))
+		for _, child := range node.children {
+			elem := reflect.New(value.Type().Elem()).Elem()
+			if err := decodeXML(child, elem); err != nil {
+				return err
+			}
+			slice = reflect.Append(slice, elem)
+		}
+		value.Set(slice)
+	case reflect.Array:
+		for i, child := range node.children {
+			if i == value.Len() {
+				break
+			}
+			if err := decodeXML(child, value.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.String:
+		value.SetString(node.text)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return fmt.Errorf("xml: element %s: %w", node.name, err)
+		}
+		value.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(text, 10, value.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("xml: element %s: %w", node.name, err)
+		}
+		value.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(text, 10, value.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("xml: element %s: %w", node.name, err)
+		}
+		value.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(text, value.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("xml: element %s: %w", node.name, err)
+		}
+		value.SetFloat(f)
+	default:
+		return fmt.Errorf("xml: cannot decode %s into %s", node.name, value.Type())
+	}
+	return nil
+}
+
+// hasBody returns true if the request carries a body to parse, of known length or not.
+func hasBody(request *http.Request) bool {
+	return request.Body != nil && request.Body != http.NoBody && request.ContentLength != 0
+}
+
+// mediaType returns the media type of the `Content-Type` header, without its parameters.
+func mediaType(request *http.Request) string {
+	contentType := request.Header.Get("Content-Type")
+	if mt, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mt
+	}
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
+
+// selectParser returns the first of the parsers handling the media type of the request,
+// or an error with status 415 if none does.
+func selectParser(parsers []Parser, request *http.Request) (Parser, *APIError) {
+	if len(parsers) == 0 {
+		parsers = DEFAULT_PARSER_CLASSES
+	}
+	mt := mediaType(request)
+	for _, parser := range parsers {
+		if slices.Contains(parser.MediaTypes(), mt) {
+			return parser, nil
+		}
+	}
+	return nil, unsupportedMediaType(mt)
+}
+
+func unsupportedMediaType(mediaType string) *APIError {
+	return NewAPIError(http.StatusUnsupportedMediaType, fmt.Sprintf("unsupported media type %q in request", mediaType), "unsupported_media_type")
+}
+
+var (
+	ErrorNotProtoMessage = errors.New("protobuf bodies can only be decoded into a proto.Message")
+	ErrorBodyTooLarge    = errors.New("request body too large")
+	ErrorNotJSONArray    = errors.New("JSON body is not an array")
+)
+
+type YAMLParser struct{}
+
+func (YAMLParser) MediaTypes() []string {
+	return []string{MIMEYAML}
+}
+
+func (YAMLParser) Parse(request *http.Request) (Decoder, error) {
+	if request == nil || request.Body == nil {
+		return nil, ErrorNoBody
+	}
+	return getYAMLDecoder(request.Body), nil
+}
+
+func (YAMLParser) ParseBody(stream []byte) Decoder {
+	return getYAMLDecoder(bytes.NewReader(stream))
+}
+
+func getYAMLDecoder(r io.Reader) Decoder {
+	decoder := yaml.NewDecoder(r)
+	return func(i any) error {
+		return decoder.Decode(i)
+	}
+}
+
+type TOMLParser struct{}
+
+func (TOMLParser) MediaTypes() []string {
+	return []string{MIMETOML}
+}
+
+func (TOMLParser) Parse(request *http.Request) (Decoder, error) {
+	if request == nil || request.Body == nil {
+		return nil, ErrorNoBody
+	}
+	return getTOMLDecoder(request.Body), nil
+}
+
+func (TOMLParser) ParseBody(stream []byte) Decoder {
+	return getTOMLDecoder(bytes.NewReader(stream))
+}
+
+func getTOMLDecoder(r io.Reader) Decoder {
+	decoder := toml.NewDecoder(r)
 	return func(i any) error {
 		return decoder.Decode(i)
 	}
 }
+
+// MsgPackParser parses MessagePack bodies sent as either of its media types,
+// matching struct fields by their json tags like MsgPackRenderer.
+type MsgPackParser struct{}
+
+func (MsgPackParser) MediaTypes() []string {
+	return []string{MIMEMSGPACK, MIMEMSGPACK2}
+}
+
+func (MsgPackParser) Parse(request *http.Request) (Decoder, error) {
+	if request == nil || request.Body == nil {
+		return nil, ErrorNoBody
+	}
+	return getMsgPackDecoder(request.Body), nil
+}
+
+func (MsgPackParser) ParseBody(stream []byte) Decoder {
+	return getMsgPackDecoder(bytes.NewReader(stream))
+}
+
+func getMsgPackDecoder(r io.Reader) Decoder {
+	decoder := msgpack.NewDecoder(r)
+	decoder.SetCustomStructTag("json")
+	return func(i any) error {
+		return decoder.Decode(i)
+	}
+}
+
+// ProtobufParser parses protobuf bodies into proto.Message targets.
+type ProtobufParser struct{}
+
+func (ProtobufParser) MediaTypes() []string {
+	return []string{MIMEPROTOBUF}
+}
+
+func (p ProtobufParser) Parse(request *http.Request) (Decoder, error) {
+	if request == nil || request.Body == nil {
+		return nil, ErrorNoBody
+	}
+	stream, err := io.ReadAll(request.Body)
+	if err != nil {
+		return nil, err
+	}
+	return p.ParseBody(stream), nil
+}
+
+func (ProtobufParser) ParseBody(stream []byte) Decoder {
+	return func(i any) error {
+		message, ok := i.(proto.Message)
+		if !ok {
+			return fmt.Errorf("%w, got %T", ErrorNotProtoMessage, i)
+		}
+		return proto.Unmarshal(stream, message)
+	}
+}
+
+var (
+	// FILE_UPLOAD_MAX_MEMORY_SIZE the size of multipart bodies MultiPartParser keeps in memory, the rest of
+	// the uploaded files being stored in temporary files.
+	FILE_UPLOAD_MAX_MEMORY_SIZE int64 = 32 << 20
+	// FILE_UPLOAD_MAX_DISK_SIZE the size of multipart bodies MultiPartParser stores in temporary files
+	// beyond FILE_UPLOAD_MAX_MEMORY_SIZE. larger bodies are answered with 413.
+	FILE_UPLOAD_MAX_DISK_SIZE int64 = 1 << 30
+)
+
+// FormParser parses url encoded form bodies, binding fields by their `form` tags.
+type FormParser struct{}
+
+func (FormParser) MediaTypes() []string {
+	return []string{MIMEPOSTForm}
+}
+
+func (FormParser) Parse(request *http.Request) (Decoder, error) {
+	if request == nil || request.Body == nil {
+		return nil, ErrorNoBody
+	}
+	// the form may already have been parsed, e.g. to read the CSRF token.
+	if err := request.ParseForm(); err != nil {
+		return nil, err
+	}
+	return getFormDecoder(request.PostForm), nil
+}
+
+func (FormParser) ParseBody(stream []byte) Decoder {
+	form, err := url.ParseQuery(string(stream))
+	if err != nil {
+		return func(any) error {
+			return err
+		}
+	}
+	return getFormDecoder(form)
+}
+
+func getFormDecoder(form url.Values) Decoder {
+	return func(i any) error {
+		return mapForm(i, form)
+	}
+}
+
+// MultiPartParser parses multipart form bodies, binding values by their `form` tags and uploaded files
+// to `*multipart.FileHeader` and `[]*multipart.FileHeader` fields.
+type MultiPartParser struct {
+	// MaxMemory the size of the body kept in memory, FILE_UPLOAD_MAX_MEMORY_SIZE if zero.
+	MaxMemory int64
+	// MaxDisk the size of the body stored in temporary files beyond MaxMemory, FILE_UPLOAD_MAX_DISK_SIZE if zero.
+	MaxDisk int64
+}
+
+func (MultiPartParser) MediaTypes() []string {
+	return []string{MIMEMultipartPOSTForm}
+}
+
+func (p MultiPartParser) limits() (memory, disk int64) {
+	memory, disk = p.MaxMemory, p.MaxDisk
+	if memory == 0 {
+		memory = FILE_UPLOAD_MAX_MEMORY_SIZE
+	}
+	if disk == 0 {
+		disk = FILE_UPLOAD_MAX_DISK_SIZE
+	}
+	return memory, disk
+}
+
+func (p MultiPartParser) Parse(request *http.Request) (Decoder, error) {
+	if request == nil || request.Body == nil {
+		return nil, ErrorNoBody
+	}
+	memory, disk := p.limits()
+	body := &limitedBody{ReadCloser: request.Body, remaining: memory + disk}
+	request.Body = body
+	if err := request.ParseMultipartForm(memory); err != nil {
+		if body.exceeded {
+			return nil, requestTooLarge()
+		}
+		return nil, err
+	}
+	return getMultipartDecoder(request.MultipartForm), nil
+}
+
+func getMultipartDecoder(form *multipart.Form) Decoder {
+	return func(i any) error {
+		return mapMultipart(i, form)
+	}
+}
+
+// limitedBody a request body failing once more than remaining bytes are read.
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+	exceeded  bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.exceeded {
+		return 0, ErrorBodyTooLarge
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	// read one byte past the limit to tell a body of exactly the limit from a larger one.
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) > b.remaining {
+		b.exceeded = true
+		n = int(b.remaining)
+		err = ErrorBodyTooLarge
+	}
+	b.remaining -= int64(n)
+	return n, err
+}
+
+func requestTooLarge() *APIError {
+	return NewAPIError(http.StatusRequestEntityTooLarge, "request body too large", "request_too_large")
+}
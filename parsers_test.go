@@ -0,0 +1,267 @@
+package django
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/alexedwards/scs/v2"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestParsers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	echo := func(ctx *gin.Context, b *bookSerializer) (int, any, error) {
+		return http.StatusOK, b, nil
+	}
+	eng := gin.New()
+	Path(eng, "/books", NewHandler[bookSerializer]().Post(echo), "")
+	Path(eng, "/json", NewHandler[bookSerializer](WithParsers[bookSerializer](JSONParser{})).Post(echo), "")
+
+	tests := []struct {
+		path, contentType, body string
+		code                    int
+		response                string
+	}{
+		{path: "/books", contentType: "application/json; charset=utf-8", body: `{"title":"dune"}`, code: http.StatusOK, response: `{"id":0,"title":"dune"}`},
+		{path: "/books", contentType: "text/xml", body: `<root><title>dune</title></root>`, code: http.StatusOK, response: `{"id":0,"title":"dune"}`},
+		{path: "/books", contentType: "application/x-yaml", body: "title: dune\n", code: http.StatusOK, response: `{"id":0,"title":"dune"}`},
+		{path: "/books", contentType: "application/toml", body: "title = 'dune'\n", code: http.StatusOK, response: `{"id":0,"title":"dune"}`},
+		{path: "/books", contentType: "application/x-msgpack", body: msgpackBook, code: http.StatusOK, response: `{"id":0,"title":"dune"}`},
+		{path: "/books", contentType: "application/msgpack", body: msgpackBook, code: http.StatusOK, response: `{"id":0,"title":"dune"}`},
+		{path: "/books", contentType: "application/x-protobuf", body: "\n\x04dune", code: http.StatusBadRequest},
+		{path: "/books", contentType: "application/json", body: `{"title":`, code: http.StatusBadRequest},
+		{path: "/books", contentType: "text/csv", body: `dune`, code: http.StatusUnsupportedMediaType,
+			response: `{"detail":"unsupported media type \"text/csv\" in request","code":"unsupported_media_type"}`},
+		{path: "/books", body: `dune`, code: http.StatusUnsupportedMediaType},
+		{path: "/json", contentType: "application/xml", body: `<book/>`, code: http.StatusUnsupportedMediaType},
+		{path: "/json", code: http.StatusOK, response: `{"id":0,"title":""}`},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, test.path, strings.NewReader(test.body))
+		if test.contentType != "" {
+			r.Header.Set("Content-Type", test.contentType)
+		}
+		eng.ServeHTTP(w, r)
+		if w.Code != test.code {
+			t.Errorf("%s %q: got %d, want %d (%s)", test.path, test.contentType, w.Code, test.code, w.Body.String())
+		}
+		if test.response != "" && w.Body.String() != test.response {
+			t.Errorf("%s %q: got %s, want %s", test.path, test.contentType, w.Body.String(), test.response)
+		}
+	}
+}
+
+// msgpackBook {"title": "dune"} encoded to MessagePack.
+const msgpackBook = "\x81\xa5title\xa4dune"
+
+func TestProtobufParser(t *testing.T) {
+	var message wrapperspb.StringValue
+	if err := (ProtobufParser{}).ParseBody([]byte("\n\x04dune")).Decode(&message); err != nil {
+		t.Fatal(err)
+	}
+	if message.GetValue() != "dune" {
+		t.Errorf("got %q, want %q", message.GetValue(), "dune")
+	}
+}
+
+type uploadSerializer struct {
+	Title       string                  `form:"title" json:"title"`
+	Document    *multipart.FileHeader   `form:"document" json:"-"`
+	Attachments []*multipart.FileHeader `form:"attachments" json:"-"`
+}
+
+func (uploadSerializer) Metadata() []Field {
+	return []Field{{Name: "title"}, {Name: "document"}, {Name: "attachments"}}
+}
+
+func TestFormParsers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	upload := func(ctx *gin.Context, s *uploadSerializer) (int, any, error) {
+		response := map[string]any{"title": s.Title}
+		if s.Document != nil {
+			response["document"] = s.Document.Filename
+		}
+		attachments := make([]string, len(s.Attachments))
+		for i, a := range s.Attachments {
+			attachments[i] = a.Filename
+		}
+		response["attachments"] = attachments
+		return http.StatusOK, response, nil
+	}
+	// uploads stored on disk are removed once answered, even when a middleware replaced the request.
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	eng := gin.New()
+	eng.Use(SessionMiddleware(scs.New()))
+	Path(eng, "/uploads", NewHandler[uploadSerializer]().Post(upload), "")
+	Path(eng, "/disk", NewHandler[uploadSerializer](WithParsers[uploadSerializer](MultiPartParser{MaxMemory: 64, MaxDisk: 1 << 20})).Post(upload), "")
+	Path(eng, "/small", NewHandler[uploadSerializer](WithParsers[uploadSerializer](MultiPartParser{MaxMemory: 64, MaxDisk: 64})).Post(upload), "")
+
+	multipartBody := func(size int) (string, string) {
+		var body bytes.Buffer
+		w := multipart.NewWriter(&body)
+		_ = w.WriteField("title", "dune")
+		for _, part := range []struct{ field, name string }{{"document", "dune.pdf"}, {"attachments", "cover.png"}, {"attachments", "map.png"}} {
+			f, _ := w.CreateFormFile(part.field, part.name)
+			_, _ = f.Write(bytes.Repeat([]byte("x"), size))
+		}
+		_ = w.Close()
+		return body.String(), w.FormDataContentType()
+	}
+	small, smallType := multipartBody(8)
+	large, largeType := multipartBody(256)
+
+	tests := []struct {
+		path, contentType, body string
+		code                    int
+		response                string
+	}{
+		{path: "/uploads", contentType: MIMEPOSTForm, body: "title=dune", code: http.StatusOK, response: `{"attachments":[],"title":"dune"}`},
+		{path: "/uploads", contentType: smallType, body: small, code: http.StatusOK, response: `{"attachments":["cover.png","map.png"],"document":"dune.pdf","title":"dune"}`},
+		{path: "/uploads", contentType: largeType, body: large, code: http.StatusOK, response: `{"attachments":["cover.png","map.png"],"document":"dune.pdf","title":"dune"}`},
+		{path: "/disk", contentType: largeType, body: large, code: http.StatusOK, response: `{"attachments":["cover.png","map.png"],"document":"dune.pdf","title":"dune"}`},
+		{path: "/small", contentType: largeType, body: large, code: http.StatusRequestEntityTooLarge},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, test.path, strings.NewReader(test.body))
+		r.Header.Set("Content-Type", test.contentType)
+		eng.ServeHTTP(w, r)
+		if w.Code != test.code {
+			t.Errorf("%s %q: got %d, want %d (%s)", test.path, test.contentType, w.Code, test.code, w.Body.String())
+		}
+		if test.response != "" && w.Body.String() != test.response {
+			t.Errorf("%s %q: got %s, want %s", test.path, test.contentType, w.Body.String(), test.response)
+		}
+	}
+	if files, err := os.ReadDir(tmp); err != nil || len(files) > 0 {
+		t.Errorf("got %d temporary files left (%v), want none", len(files), err)
+	}
+}
+
+type filterSerializer struct {
+	ID    uint     `uri:"id" query:"id" json:"id"`
+	Title string   `query:"title" json:"title"`
+	Tags  []string `query:"tag" json:"tags"`
+}
+
+func (filterSerializer) Metadata() []Field {
+	return []Field{{Name: "id"}, {Name: "title"}, {Name: "tags"}}
+}
+
+func TestUriParsers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	echo := func(ctx *gin.Context, s *filterSerializer) (int, any, error) {
+		return http.StatusOK, s, nil
+	}
+	eng := gin.New()
+	Path(eng, "/books", NewHandler[filterSerializer]().Get(echo), "")
+	Path(eng, "/books/<int:id>", NewHandler[filterSerializer]().Get(echo).Put(echo), "")
+
+	tests := []struct {
+		method, path, body string
+		response           string
+	}{
+		{method: http.MethodGet, path: "/books?title=dune&tag=scifi&tag=classic",
+			response: `{"id":0,"title":"dune","tags":["scifi","classic"]}`},
+		{method: http.MethodGet, path: "/books/2?id=3&title=dune",
+			response: `{"id":2,"title":"dune","tags":null}`},
+		{method: http.MethodPut, path: "/books/2?title=dune&tag=scifi", body: `{"id":4,"title":"emma"}`,
+			response: `{"id":2,"title":"emma","tags":["scifi"]}`},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+		if test.body != "" {
+			r.Header.Set("Content-Type", MIMEJSON)
+		}
+		eng.ServeHTTP(w, r)
+		if w.Code != http.StatusOK || w.Body.String() != test.response {
+			t.Errorf("%s %s: got %d %s, want 200 %s", test.method, test.path, w.Code, w.Body.String(), test.response)
+		}
+	}
+}
+
+type accountSerializer struct {
+	Username string `query:"username" json:"username"`
+	IsStaff  bool   `json:"is_staff"`
+}
+
+func (accountSerializer) Metadata() []Field {
+	return []Field{{Name: "username"}, {Name: "is_staff"}}
+}
+
+func TestQueryParserTagged(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	eng := gin.New()
+	Path(eng, "/users", NewHandler[accountSerializer]().Post(func(ctx *gin.Context, s *accountSerializer) (int, any, error) {
+		return http.StatusOK, s, nil
+	}), "")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/users?IsStaff=true&username=alice", strings.NewReader(`{}`))
+	r.Header.Set("Content-Type", MIMEJSON)
+	eng.ServeHTTP(w, r)
+	if want := `{"username":"alice","is_staff":false}`; w.Code != http.StatusOK || w.Body.String() != want {
+		t.Errorf("got %d %s, want 200 %s", w.Code, w.Body.String(), want)
+	}
+}
+
+func TestJSONStream(t *testing.T) {
+	type row struct {
+		Title string `json:"title"`
+		Year  int    `json:"year"`
+	}
+	tests := []struct {
+		name  string
+		body  string
+		rows  []row
+		bad   []int
+		fatal bool
+	}{
+		{name: "empty", body: `[]`},
+		{name: "rows", body: `[{"title":"dune","year":1965}, {"title":"emma","year":1815}]`, rows: []row{{"dune", 1965}, {"emma", 1815}}},
+		{name: "bad element", body: `[{"title":"dune","year":"1965"}, {"title":"emma","year":1815}]`, rows: []row{{"emma", 1815}}, bad: []int{0}},
+		{name: "truncated", body: `[{"title":"dune","year":1965}, {"title":`, rows: []row{{"dune", 1965}}, fatal: true},
+		{name: "unterminated", body: `[{"title":"dune","year":1965}`, rows: []row{{"dune", 1965}}, fatal: true},
+		{name: "not an array", body: `{"title":"dune"}`, fatal: true},
+	}
+	for _, test := range tests {
+		var (
+			rows  []row
+			bad   []int
+			fatal error
+		)
+		stream := NewJSONStream(strings.NewReader(test.body))
+		for {
+			var r row
+			err := stream.Next(&r)
+			if err == io.EOF {
+				break
+			}
+			var elementErr *ElementError
+			if errors.As(err, &elementErr) {
+				bad = append(bad, elementErr.Index)
+				continue
+			}
+			if err != nil {
+				fatal = err
+				break
+			}
+			rows = append(rows, r)
+		}
+		if !reflect.DeepEqual(rows, test.rows) || !reflect.DeepEqual(bad, test.bad) || (fatal != nil) != test.fatal {
+			t.Errorf("%s: got rows %v, bad elements %v, error %v", test.name, rows, bad, fatal)
+		}
+	}
+}
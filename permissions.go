@@ -1,7 +1,10 @@
 package django
 
 import (
+	"fmt"
 	"net/http"
+	"reflect"
+	"strings"
 
 	"golang.org/x/exp/slices"
 )
@@ -12,6 +15,17 @@ var (
 		http.MethodHead,
 		http.MethodOptions,
 	}
+	// DEFAULT_MODEL_PERMISSIONS_MAP the permissions ModelPermissions requires for each method,
+	// "%[1]s" is replaced by the app label and "%[2]s" by the model name.
+	DEFAULT_MODEL_PERMISSIONS_MAP = map[string][]string{
+		http.MethodGet:     {"%[1]s.view_%[2]s"},
+		http.MethodHead:    {"%[1]s.view_%[2]s"},
+		http.MethodOptions: {},
+		http.MethodPost:    {"%[1]s.add_%[2]s"},
+		http.MethodPut:     {"%[1]s.change_%[2]s"},
+		http.MethodPatch:   {"%[1]s.change_%[2]s"},
+		http.MethodDelete:  {"%[1]s.delete_%[2]s"},
+	}
 )
 
 type PermissionClass interface {
@@ -19,17 +33,109 @@ type PermissionClass interface {
 	HasPermission(request *Request) bool
 }
 
+// ObjectPermissionClass is implemented by permission classes that also check access to a single object.
+// views call it through Request.CheckObjectPermissions once the object has been fetched.
+type ObjectPermissionClass interface {
+	// HasObjectPermission return true if permission to the object is granted, false otherwise.
+	HasObjectPermission(request *Request, obj any) bool
+}
+
+// hasObjectPermission grants permission to objects unless the permission class checks them.
+func hasObjectPermission(permission PermissionClass, request *Request, obj any) bool {
+	if p, ok := permission.(ObjectPermissionClass); ok {
+		return p.HasObjectPermission(request, obj)
+	}
+	return true
+}
+
+// PermissionMessage is implemented by permission classes that describe why a request was denied.
+type PermissionMessage interface {
+	// Message returns the detail of the response sent when permission is denied.
+	Message() string
+}
+
+// PermissionCode is implemented by permission classes that identify their denials with a code.
+type PermissionCode interface {
+	// Code returns the code of the response sent when permission is denied.
+	Code() string
+}
+
+// permissionDenied returns the error a request denied by permission is answered with: 401 if the request
+// is not authenticated and its first authenticator challenges the client with a `WWW-Authenticate` header,
+// 403 otherwise.
+func permissionDenied(request *Request, permission PermissionClass) *APIError {
+	message := "you do not have permission to perform this action"
+	if m, ok := permission.(PermissionMessage); ok && m.Message() != "" {
+		message = m.Message()
+	}
+	status, code := http.StatusForbidden, "permission_denied"
+	if !request.User.IsAuthenticated() {
+		status, code = request.unauthenticatedStatus(), "not_authenticated"
+	}
+	if c, ok := permission.(PermissionCode); ok && c.Code() != "" {
+		code = c.Code()
+	}
+	return NewAPIError(status, message, code)
+}
+
+// composite is implemented by permission classes combining other permission classes,
+// reporting which of them caused a denial.
+type composite interface {
+	deniedBy(request *Request) PermissionClass
+	objectDeniedBy(request *Request, obj any) PermissionClass
+}
+
+// deniedBy returns the permission class responsible for denying the request, nil if permission is granted.
+func deniedBy(permission PermissionClass, request *Request) PermissionClass {
+	if c, ok := permission.(composite); ok {
+		return c.deniedBy(request)
+	}
+	if !permission.HasPermission(request) {
+		return permission
+	}
+	return nil
+}
+
+// objectDeniedBy returns the permission class responsible for denying access to obj, nil if permission is granted.
+func objectDeniedBy(permission PermissionClass, request *Request, obj any) PermissionClass {
+	if c, ok := permission.(composite); ok {
+		return c.objectDeniedBy(request, obj)
+	}
+	if !hasObjectPermission(permission, request, obj) {
+		return permission
+	}
+	return nil
+}
+
 type and struct {
 	permissions []PermissionClass
 }
 
 func (p and) HasPermission(request *Request) bool {
+	return p.deniedBy(request) == nil
+}
+
+func (p and) HasObjectPermission(request *Request, obj any) bool {
+	return p.objectDeniedBy(request, obj) == nil
+}
+
+// deniedBy reports the first permission class denying the request.
+func (p and) deniedBy(request *Request) PermissionClass {
 	for _, permission := range p.permissions {
-		if ok := permission.HasPermission(request); !ok {
-			return false
+		if denied := deniedBy(permission, request); denied != nil {
+			return denied
 		}
 	}
-	return true
+	return nil
+}
+
+func (p and) objectDeniedBy(request *Request, obj any) PermissionClass {
+	for _, permission := range p.permissions {
+		if denied := objectDeniedBy(permission, request, obj); denied != nil {
+			return denied
+		}
+	}
+	return nil
 }
 
 func And(permissions ...PermissionClass) PermissionClass {
@@ -43,12 +149,52 @@ type or struct {
 }
 
 func (p or) HasPermission(request *Request) bool {
+	return p.deniedBy(request) == nil
+}
+
+// HasObjectPermission grants permission when one of the permission classes grants both
+// permission to the request and to the object.
+func (p or) HasObjectPermission(request *Request, obj any) bool {
+	return p.objectDeniedBy(request, obj) == nil
+}
+
+// deniedBy reports the denial of the first permission class when every permission class denies the request.
+// an or of no permission classes denies every request.
+func (p or) deniedBy(request *Request) PermissionClass {
+	if len(p.permissions) == 0 {
+		return p
+	}
+	var first PermissionClass
 	for _, permission := range p.permissions {
-		if ok := permission.HasPermission(request); ok {
-			return true
+		denied := deniedBy(permission, request)
+		if denied == nil {
+			return nil
+		}
+		if first == nil {
+			first = denied
 		}
 	}
-	return false
+	return first
+}
+
+func (p or) objectDeniedBy(request *Request, obj any) PermissionClass {
+	if len(p.permissions) == 0 {
+		return p
+	}
+	var first PermissionClass
+	for _, permission := range p.permissions {
+		denied := deniedBy(permission, request)
+		if denied == nil {
+			denied = objectDeniedBy(permission, request, obj)
+		}
+		if denied == nil {
+			return nil
+		}
+		if first == nil {
+			first = denied
+		}
+	}
+	return first
 }
 
 func Or(permissions ...PermissionClass) PermissionClass {
@@ -57,6 +203,26 @@ func Or(permissions ...PermissionClass) PermissionClass {
 	}
 }
 
+type not struct {
+	permission PermissionClass
+}
+
+func (p not) HasPermission(request *Request) bool {
+	return !p.permission.HasPermission(request)
+}
+
+// HasObjectPermission grants permission when the permission class denies either the request or the object.
+func (p not) HasObjectPermission(request *Request, obj any) bool {
+	return !(p.permission.HasPermission(request) && hasObjectPermission(p.permission, request, obj))
+}
+
+// Not inverts the permission class. denials are reported as coming from the returned permission class.
+func Not(permission PermissionClass) PermissionClass {
+	return not{
+		permission: permission,
+	}
+}
+
 // BasePermission a base struct from which all permission classes should be composed.
 type BasePermission struct{}
 
@@ -64,6 +230,10 @@ func (p BasePermission) HasPermission(request *Request) bool {
 	return true
 }
 
+func (p BasePermission) HasObjectPermission(request *Request, obj any) bool {
+	return true
+}
+
 type AllowAny struct {
 	BasePermission
 }
@@ -77,7 +247,11 @@ type IsAuthenticated struct {
 }
 
 func (p IsAuthenticated) HasPermission(request *Request) bool {
-	return request.User != nil && request.User.IsAuthenticated()
+	return request.User.IsAuthenticated()
+}
+
+func (p IsAuthenticated) Message() string {
+	return "authentication credentials were not provided"
 }
 
 type IsAdminUser struct {
@@ -85,7 +259,11 @@ type IsAdminUser struct {
 }
 
 func (p IsAdminUser) HasPermission(request *Request) bool {
-	return request.User != nil && request.User.IsAdmin()
+	return request.User.IsAdmin()
+}
+
+func (p IsAdminUser) Message() string {
+	return "administrator privileges are required"
 }
 
 type IsAuthenticatedOrReadOnly struct {
@@ -93,5 +271,95 @@ type IsAuthenticatedOrReadOnly struct {
 }
 
 func (p IsAuthenticatedOrReadOnly) HasPermission(request *Request) bool {
-	return slices.Contains(SAFE_METHODS, request.Method) || (request.User != nil && request.User.IsAuthenticated())
+	return slices.Contains(SAFE_METHODS, request.Method) || request.User.IsAuthenticated()
+}
+
+// IsOwnerOrReadOnly allows safe methods to anyone, and other methods only to the owner of the object.
+// the owner is read from the exported OwnerField of the object, which holds the owning User, its id or its username.
+// a username is matched against the user of the request, an id or User only against users implementing IdentifiedUser.
+type IsOwnerOrReadOnly struct {
+	BasePermission
+	OwnerField string
+}
+
+// NewIsOwnerOrReadOnly creates an IsOwnerOrReadOnly reading the owner from ownerField, "OwnerID" by default.
+func NewIsOwnerOrReadOnly(ownerField ...string) PermissionClass {
+	p := IsOwnerOrReadOnly{
+		OwnerField: "OwnerID",
+	}
+	if len(ownerField) > 0 {
+		p.OwnerField = ownerField[0]
+	}
+	return p
+}
+
+func (p IsOwnerOrReadOnly) HasObjectPermission(request *Request, obj any) bool {
+	if slices.Contains(SAFE_METHODS, request.Method) {
+		return true
+	}
+	if !request.User.IsAuthenticated() {
+		return false
+	}
+	value := reflect.Indirect(reflect.ValueOf(obj))
+	if value.Kind() != reflect.Struct {
+		return false
+	}
+	owner := value.FieldByName(p.OwnerField)
+	if !owner.IsValid() || !owner.CanInterface() {
+		return false
+	}
+	id, identified := userID(request.User)
+	if u, ok := owner.Interface().(User); ok {
+		ownerID, ownerIdentified := userID(u)
+		return identified && ownerIdentified && ownerID == id
+	}
+	owner = reflect.Indirect(owner)
+	switch owner.Kind() {
+	case reflect.String:
+		return owner.String() == request.User.Username()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return identified && owner.Int() >= 0 && uint64(owner.Int()) == uint64(id)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return identified && owner.Uint() == uint64(id)
+	}
+	return false
+}
+
+func (p IsOwnerOrReadOnly) Message() string {
+	return "only the owner may modify this object"
+}
+
+// ModelPermissions grants authenticated users the permissions on the model that the method of the request requires,
+// e.g. "orders.change_order" to PUT an order. methods missing from PermsMap are denied.
+type ModelPermissions struct {
+	BasePermission
+	// Model the model behind the view, as "<app label>.<model name>", e.g. "orders.order".
+	Model    string
+	PermsMap map[string][]string
+}
+
+// NewModelPermissions creates a ModelPermissions for the model, "<app label>.<model name>",
+// requiring the permissions of DEFAULT_MODEL_PERMISSIONS_MAP.
+func NewModelPermissions(model string) PermissionClass {
+	return ModelPermissions{
+		Model:    model,
+		PermsMap: DEFAULT_MODEL_PERMISSIONS_MAP,
+	}
+}
+
+func (p ModelPermissions) HasPermission(request *Request) bool {
+	if !request.User.IsAuthenticated() {
+		return false
+	}
+	perms, ok := p.PermsMap[request.Method]
+	if !ok {
+		return false
+	}
+	app, model, _ := strings.Cut(p.Model, ".")
+	for _, perm := range perms {
+		if !hasPerm(request.User, fmt.Sprintf(perm, app, model)) {
+			return false
+		}
+	}
+	return true
 }
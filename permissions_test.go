@@ -0,0 +1,245 @@
+package django
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+type testUser struct {
+	id       uint
+	username string
+	password string
+	admin    bool
+}
+
+func (u testUser) ID() uint {
+	return u.id
+}
+
+func (u testUser) ValidatePassword(password string) error {
+	if password != u.password {
+		return errors.New("invalid password")
+	}
+	return nil
+}
+
+func (u testUser) IsAuthenticated() bool {
+	return true
+}
+
+func (u testUser) IsAdmin() bool {
+	return u.admin
+}
+
+func (u testUser) Username() string {
+	return u.username
+}
+
+type denyWithMessage struct {
+	BasePermission
+	message, code string
+}
+
+func (p denyWithMessage) HasPermission(_ *Request) bool {
+	return false
+}
+
+func (p denyWithMessage) Message() string {
+	return p.message
+}
+
+func (p denyWithMessage) Code() string {
+	return p.code
+}
+
+func TestCheckPermissions(t *testing.T) {
+	user := testUser{id: 1, username: "alice"}
+	first := denyWithMessage{message: "first", code: "first_denied"}
+	second := denyWithMessage{message: "second", code: "second_denied"}
+
+	tests := []struct {
+		name           string
+		permission     PermissionClass
+		user           User
+		authenticators []AuthenticationClass
+		wantStatus     int
+		wantMessage    string
+		wantCode       string
+	}{
+		{name: "allow any", permission: AllowAny{}},
+		{name: "anonymous", permission: IsAuthenticated{}, user: AnonymousUser{}, authenticators: []AuthenticationClass{NewBasicAuthentication(testUserProvider{})}, wantStatus: http.StatusUnauthorized, wantMessage: IsAuthenticated{}.Message(), wantCode: "not_authenticated"},
+		{name: "anonymous without challenge", permission: IsAuthenticated{}, user: AnonymousUser{}, authenticators: []AuthenticationClass{BaseAuthentication{}}, wantStatus: http.StatusForbidden, wantMessage: IsAuthenticated{}.Message(), wantCode: "not_authenticated"},
+		{name: "anonymous without authenticators", permission: IsAuthenticated{}, user: AnonymousUser{}, wantStatus: http.StatusForbidden, wantMessage: IsAuthenticated{}.Message(), wantCode: "not_authenticated"},
+		{name: "not admin", permission: IsAdminUser{}, user: user, wantStatus: http.StatusForbidden, wantMessage: IsAdminUser{}.Message(), wantCode: "permission_denied"},
+		{name: "and reports the failing permission", permission: And(AllowAny{}, second, first), user: user, wantStatus: http.StatusForbidden, wantMessage: "second", wantCode: "second_denied"},
+		{name: "or grants", permission: Or(first, AllowAny{}), user: user},
+		{name: "empty or denies", permission: Or(), user: user, wantStatus: http.StatusForbidden, wantMessage: "you do not have permission to perform this action", wantCode: "permission_denied"},
+		{name: "or reports the first denial", permission: Or(first, second), user: user, wantStatus: http.StatusForbidden, wantMessage: "first", wantCode: "first_denied"},
+		{name: "nested", permission: And(Or(AllowAny{}, first), Or(second, And(AllowAny{}, first))), user: user, wantStatus: http.StatusForbidden, wantMessage: "second", wantCode: "second_denied"},
+		{name: "not grants", permission: Not(first), user: user},
+		{name: "not denies", permission: Not(AllowAny{}), user: user, wantStatus: http.StatusForbidden, wantMessage: "you do not have permission to perform this action", wantCode: "permission_denied"},
+	}
+	for _, test := range tests {
+		request := &Request{
+			User:           test.user,
+			Authenticators: test.authenticators,
+			Permissions:    []PermissionClass{test.permission},
+			Request:        &http.Request{Method: http.MethodPost},
+		}
+		err := request.checkPermissions()
+		if test.wantStatus == 0 {
+			if err != nil {
+				t.Errorf("%s: got error %v, want permission granted", test.name, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%s: got permission granted, want status %d", test.name, test.wantStatus)
+			continue
+		}
+		if err.Status != test.wantStatus || err.Detail != test.wantMessage || err.Code != test.wantCode {
+			t.Errorf("%s: got %d %q %q, want %d %q %q", test.name, err.Status, err.Detail, err.Code, test.wantStatus, test.wantMessage, test.wantCode)
+		}
+	}
+}
+
+func TestCheckObjectPermissions(t *testing.T) {
+	type document struct {
+		OwnerID uint
+	}
+	owner := testUser{id: 1, username: "alice"}
+	other := testUser{id: 2, username: "bob"}
+	admin := testUser{id: 3, username: "carol", admin: true}
+	permission := Or(IsAdminUser{}, NewIsOwnerOrReadOnly())
+
+	tests := []struct {
+		name   string
+		method string
+		user   User
+		want   bool
+	}{
+		{name: "read", method: http.MethodGet, user: other, want: true},
+		{name: "owner writes", method: http.MethodPut, user: owner, want: true},
+		{name: "other writes", method: http.MethodPut, user: other, want: false},
+		{name: "admin writes", method: http.MethodDelete, user: admin, want: true},
+		{name: "anonymous writes", method: http.MethodDelete, user: AnonymousUser{}, want: false},
+	}
+	for _, test := range tests {
+		request := &Request{
+			User:        test.user,
+			Permissions: []PermissionClass{permission},
+			Request:     &http.Request{Method: test.method},
+		}
+		err := request.CheckObjectPermissions(&document{OwnerID: owner.id})
+		if got := err == nil; got != test.want {
+			t.Errorf("%s: got permission granted %t, want %t (%v)", test.name, got, test.want, err)
+		}
+	}
+
+	request := &Request{User: admin, Permissions: []PermissionClass{Or()}, Request: &http.Request{Method: http.MethodGet}}
+	if err := request.CheckObjectPermissions(&document{OwnerID: owner.id}); err == nil {
+		t.Error("empty or: got permission granted, want denied")
+	}
+}
+
+// namedUser a user without an id.
+type namedUser struct {
+	username string
+}
+
+func (u namedUser) ValidatePassword(_ string) error {
+	return nil
+}
+
+func (u namedUser) IsAuthenticated() bool {
+	return true
+}
+
+func (u namedUser) IsAdmin() bool {
+	return false
+}
+
+func (u namedUser) Username() string {
+	return u.username
+}
+
+func TestIsOwnerOrReadOnly(t *testing.T) {
+	type byID struct {
+		OwnerID uint
+	}
+	type byUsername struct {
+		Owner string
+	}
+	type byUser struct {
+		Owner User
+	}
+	type unexported struct {
+		owner uint
+	}
+	alice := testUser{id: 1, username: "alice"}
+
+	tests := []struct {
+		name       string
+		permission PermissionClass
+		user       User
+		obj        any
+		want       bool
+	}{
+		{name: "owner id", permission: NewIsOwnerOrReadOnly(), user: alice, obj: byID{OwnerID: 1}, want: true},
+		{name: "other id", permission: NewIsOwnerOrReadOnly(), user: alice, obj: byID{OwnerID: 2}, want: false},
+		{name: "owner username", permission: NewIsOwnerOrReadOnly("Owner"), user: alice, obj: &byUsername{Owner: "alice"}, want: true},
+		{name: "owner user", permission: NewIsOwnerOrReadOnly("Owner"), user: alice, obj: byUser{Owner: testUser{id: 1}}, want: true},
+		{name: "no owner", permission: NewIsOwnerOrReadOnly("Owner"), user: alice, obj: byUser{}, want: false},
+		{name: "missing field", permission: NewIsOwnerOrReadOnly("Author"), user: alice, obj: byID{OwnerID: 1}, want: false},
+		{name: "unexported field", permission: NewIsOwnerOrReadOnly("owner"), user: alice, obj: unexported{owner: 1}, want: false},
+		{name: "user without id by username", permission: NewIsOwnerOrReadOnly("Owner"), user: namedUser{username: "alice"}, obj: byUsername{Owner: "alice"}, want: true},
+		{name: "user without id by id", permission: NewIsOwnerOrReadOnly(), user: namedUser{username: "alice"}, obj: byID{}, want: false},
+	}
+	for _, test := range tests {
+		request := &Request{User: test.user, Request: &http.Request{Method: http.MethodPut}}
+		if got := hasObjectPermission(test.permission, request, test.obj); got != test.want {
+			t.Errorf("%s: got %t, want %t", test.name, got, test.want)
+		}
+	}
+}
+
+type permUser struct {
+	testUser
+	perms []string
+}
+
+func (u permUser) HasPerm(codename string) bool {
+	for _, perm := range u.perms {
+		if perm == codename {
+			return true
+		}
+	}
+	return false
+}
+
+func TestModelPermissions(t *testing.T) {
+	clerk := permUser{testUser: testUser{id: 1, username: "alice"}, perms: []string{"orders.view_order", "orders.change_order"}}
+	permission := NewModelPermissions("orders.order")
+
+	tests := []struct {
+		method string
+		user   User
+		want   bool
+	}{
+		{method: http.MethodGet, user: clerk, want: true},
+		{method: http.MethodPatch, user: clerk, want: true},
+		{method: http.MethodPost, user: clerk, want: false},
+		{method: http.MethodDelete, user: clerk, want: false},
+		{method: http.MethodOptions, user: clerk, want: true},
+		{method: http.MethodGet, user: testUser{id: 2, username: "bob"}, want: false},
+		{method: http.MethodOptions, user: AnonymousUser{}, want: false},
+		{method: "TRACE", user: clerk, want: false},
+	}
+	for _, test := range tests {
+		request := &Request{User: test.user, Request: &http.Request{Method: test.method}}
+		if got := permission.HasPermission(request); got != test.want {
+			t.Errorf("%s as %q: got %t, want %t", test.method, test.user.Username(), got, test.want)
+		}
+	}
+}
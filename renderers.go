@@ -0,0 +1,470 @@
+package django
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vmihailenco/msgpack/v5"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	// DEFAULT_RENDERER_CLASSES the renderers used by handlers configured without renderers.
+	// the first one is used when the client accepts any media type.
+	DEFAULT_RENDERER_CLASSES = []Renderer{
+		JSONRenderer{},
+		XMLRenderer{},
+		YAMLRenderer{},
+		MsgPackRenderer{},
+	}
+)
+
+// Renderer renders response data to a media type.
+type Renderer interface {
+	// MediaTypes returns the media types the renderer produces, the first being its preferred one.
+	MediaTypes() []string
+	// Format returns the name selecting the renderer in the `format` query parameter, e.g. "json".
+	Format() string
+	Render(w io.Writer, data any) error
+}
+
+// RendererCharset is implemented by renderers producing text, announcing its charset in the `Content-Type` header.
+type RendererCharset interface {
+	Charset() string
+}
+
+type JSONRenderer struct{}
+
+func (JSONRenderer) MediaTypes() []string {
+	return []string{MIMEJSON}
+}
+
+func (JSONRenderer) Format() string {
+	return "json"
+}
+
+func (JSONRenderer) Charset() string {
+	return "utf-8"
+}
+
+func (JSONRenderer) Render(w io.Writer, data any) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// XMLRenderer renders data under a `root` element, the way it is rendered to JSON:
+// maps and structs become elements named after their keys and json field names, slices become `list-item` elements.
+// keys that are not valid XML names become `item` elements holding the key in a `key` attribute.
+// values implementing xml.Marshaler render themselves. XMLParser parses the rendered documents back.
+type XMLRenderer struct{}
+
+func (XMLRenderer) MediaTypes() []string {
+	return []string{MIMEXML, MIMEXML2}
+}
+
+func (XMLRenderer) Format() string {
+	return "xml"
+}
+
+func (XMLRenderer) Charset() string {
+	return "utf-8"
+}
+
+func (XMLRenderer) Render(w io.Writer, data any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	if err := encodeXML(encoder, "root", reflect.ValueOf(data)); err != nil {
+		return err
+	}
+	return encoder.Flush()
+}
+
+var (
+	xmlMarshalerType  = reflect.TypeOf((*xml.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// xmlItem the element of keys that are not valid XML names.
+const xmlItem = "item"
+
+// isXMLName returns true if name can name an element. names with a namespace prefix are not accepted.
+func isXMLName(name string) bool {
+	for i, r := range name {
+		if unicode.IsLetter(r) || r == '_' || (i > 0 && (unicode.IsDigit(r) || r == '-' || r == '.')) {
+			continue
+		}
+		return false
+	}
+	return name != ""
+}
+
+// xmlStart returns the start of the element of the key name.
+func xmlStart(name string) xml.StartElement {
+	if isXMLName(name) {
+		return xml.StartElement{Name: xml.Name{Local: name}}
+	}
+	return xml.StartElement{
+		Name: xml.Name{Local: xmlItem},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "key"}, Value: name}},
+	}
+}
+
+func encodeXML(encoder *xml.Encoder, name string, value reflect.Value) error {
+	start := xmlStart(name)
+	for value.IsValid() && (value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface) {
+		if value.IsNil() {
+			return encoder.EncodeElement("", start)
+		}
+		if value.Type().Implements(xmlMarshalerType) || value.Type().Implements(textMarshalerType) {
+			break
+		}
+		value = value.Elem()
+	}
+	if !value.IsValid() {
+		return encoder.EncodeElement("", start)
+	}
+	if value.Type().Implements(xmlMarshalerType) || value.Type().Implements(textMarshalerType) {
+		return encoder.EncodeElement(value.Interface(), start)
+	}
+
+	switch value.Kind() {
+	case reflect.Map:
+		if err := encoder.EncodeToken(start); err != nil {
+			return err
+		}
+		keys := value.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		for _, key := range keys {
+			if err := encodeXML(encoder, fmt.Sprint(key.Interface()), value.MapIndex(key)); err != nil {
+				return err
+			}
+		}
+		return encoder.EncodeToken(start.End())
+	case reflect.Struct:
+		if err := encoder.EncodeToken(start); err != nil {
+			return err
+		}
+		if err := encodeXMLFields(encoder, value); err != nil {
+			return err
+		}
+		return encoder.EncodeToken(start.End())
+	case reflect.Slice, reflect.Array:
+		if value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.Uint8 {
+			return encoder.EncodeElement(value.Interface(), start)
+		}
+		if err := encoder.EncodeToken(start); err != nil {
+			return err
+		}
+		for i := 0; i < value.Len(); i++ {
+			if err := encodeXML(encoder, "list-item", value.Index(i)); err != nil {
+				return err
+			}
+		}
+		return encoder.EncodeToken(start.End())
+	}
+	return encoder.EncodeElement(value.Interface(), start)
+}
+
+// encodeXMLFields encodes the exported fields of a struct under their json names, flattening embedded structs.
+func encodeXMLFields(encoder *xml.Encoder, value reflect.Value) error {
+	return jsonFields(value, false, func(name string, omitEmpty bool, field reflect.Value) error {
+		if omitEmpty && field.IsZero() {
+			return nil
+		}
+		return encodeXML(encoder, name, field)
+	})
+}
+
+// jsonFields calls fn with the exported fields of a struct under their json names, flattening embedded structs.
+// nil embedded struct pointers are skipped, or allocated when alloc is true.
+func jsonFields(value reflect.Value, alloc bool, fn func(name string, omitEmpty bool, field reflect.Value) error) error {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+		fieldValue := value.Field(i)
+		if field.Anonymous && name == "" {
+			if alloc && fieldValue.Kind() == reflect.Pointer && fieldValue.IsNil() && fieldValue.Type().Elem().Kind() == reflect.Struct && fieldValue.CanSet() {
+				fieldValue.Set(reflect.New(fieldValue.Type().Elem()))
+			}
+			embedded := reflect.Indirect(fieldValue)
+			if embedded.Kind() == reflect.Struct {
+				if err := jsonFields(embedded, alloc, fn); err != nil {
+					return err
+				}
+				continue
+			}
+			if !field.IsExported() {
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if err := fn(name, strings.Contains(opts, "omitempty"), fieldValue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// YAMLRenderer renders data to YAML through its JSON encoding, naming struct fields by their json tags
+// and keeping the order of their keys.
+type YAMLRenderer struct{}
+
+func (YAMLRenderer) MediaTypes() []string {
+	return []string{MIMEYAML}
+}
+
+func (YAMLRenderer) Format() string {
+	return "yaml"
+}
+
+func (YAMLRenderer) Charset() string {
+	return "utf-8"
+}
+
+func (YAMLRenderer) Render(w io.Writer, data any) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	value, err := yamlValue(decoder)
+	if err != nil {
+		return err
+	}
+	return yaml.NewEncoder(w).Encode(value)
+}
+
+// yamlValue reads the next JSON value of the decoder, decoding objects to yaml.MapSlice to keep the order of their keys.
+func yamlValue(decoder *json.Decoder) (any, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token := token.(type) {
+	case json.Delim:
+		if token == '{' {
+			m := yaml.MapSlice{}
+			for decoder.More() {
+				key, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				value, err := yamlValue(decoder)
+				if err != nil {
+					return nil, err
+				}
+				m = append(m, yaml.MapItem{Key: key, Value: value})
+			}
+			_, err = decoder.Token()
+			return m, err
+		}
+		s := []any{}
+		for decoder.More() {
+			value, err := yamlValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			s = append(s, value)
+		}
+		_, err = decoder.Token()
+		return s, err
+	case json.Number:
+		if i, err := token.Int64(); err == nil {
+			return i, nil
+		}
+		if u, err := strconv.ParseUint(token.String(), 10, 64); err == nil {
+			return u, nil
+		}
+		return token.Float64()
+	}
+	return token, nil
+}
+
+// MsgPackRenderer renders data to MessagePack, naming struct fields by their json tags.
+type MsgPackRenderer struct{}
+
+func (MsgPackRenderer) MediaTypes() []string {
+	return []string{MIMEMSGPACK, MIMEMSGPACK2}
+}
+
+func (MsgPackRenderer) Format() string {
+	return "msgpack"
+}
+
+func (MsgPackRenderer) Render(w io.Writer, data any) error {
+	encoder := msgpack.NewEncoder(w)
+	encoder.SetCustomStructTag("json")
+	return encoder.Encode(data)
+}
+
+// mediaRange a media range of the `Accept` header.
+type mediaRange struct {
+	mediaType string
+	q         float64
+}
+
+// specificity ranks `*/*` below `type/*` below `type/subtype`.
+func (m mediaRange) specificity() int {
+	switch {
+	case m.mediaType == "*/*":
+		return 0
+	case strings.HasSuffix(m.mediaType, "/*"):
+		return 1
+	}
+	return 2
+}
+
+func (m mediaRange) match(mediaType string) bool {
+	switch m.specificity() {
+	case 0:
+		return true
+	case 1:
+		return strings.HasPrefix(mediaType, strings.TrimSuffix(m.mediaType, "*"))
+	}
+	return m.mediaType == mediaType
+}
+
+// parseAccept returns the media ranges of the `Accept` header.
+func parseAccept(header string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		r := mediaRange{
+			mediaType: strings.ToLower(strings.TrimSpace(params[0])),
+			q:         1,
+		}
+		if r.mediaType == "" {
+			continue
+		}
+		for _, param := range params[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(key, "q") {
+				if q, err := strconv.ParseFloat(value, 64); err == nil {
+					r.q = q
+				}
+			}
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges
+}
+
+// quality returns the quality the media ranges give to the media type: the quality of the most specific
+// range matching it, 0 if none does.
+func quality(ranges []mediaRange, mediaType string) float64 {
+	q, specificity := 0.0, -1
+	for _, r := range ranges {
+		if r.specificity() > specificity && r.match(mediaType) {
+			q, specificity = r.q, r.specificity()
+		}
+	}
+	return q
+}
+
+// negotiate selects the renderer of the response, and its media type, from the `format` query parameter
+// or else the `Accept` header: the media type of highest quality wins, ties going to the first renderer.
+// it returns an error with status 406 if no renderer is acceptable.
+func (r *Request) negotiate(renderers []Renderer) *APIError {
+	if len(renderers) == 0 {
+		renderers = DEFAULT_RENDERER_CLASSES
+	}
+	notAcceptable := NewAPIError(http.StatusNotAcceptable, "could not satisfy the request Accept header", "not_acceptable")
+
+	if format := r.URL.Query().Get("format"); format != "" {
+		for _, renderer := range renderers {
+			if renderer.Format() == format {
+				r.AcceptedRenderer, r.AcceptedMediaType = renderer, renderer.MediaTypes()[0]
+				return nil
+			}
+		}
+		return notAcceptable
+	}
+
+	header := r.Header.Get("Accept")
+	if header == "" {
+		r.AcceptedRenderer, r.AcceptedMediaType = renderers[0], renderers[0].MediaTypes()[0]
+		return nil
+	}
+	ranges := parseAccept(header)
+	best := 0.0
+	for _, renderer := range renderers {
+		for _, mediaType := range renderer.MediaTypes() {
+			if q := quality(ranges, mediaType); q > best {
+				best = q
+				r.AcceptedRenderer, r.AcceptedMediaType = renderer, mediaType
+			}
+		}
+	}
+	if r.AcceptedRenderer == nil {
+		return notAcceptable
+	}
+	return nil
+}
+
+// rendered a gin render.Render writing data with the renderer negotiated for the request.
+type rendered struct {
+	renderer    Renderer
+	contentType string
+	data        any
+}
+
+func (r rendered) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	return r.renderer.Render(w, r.data)
+}
+
+func (r rendered) WriteContentType(w http.ResponseWriter) {
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", r.contentType)
+	}
+}
+
+// respond renders data with the renderer negotiated for the request. the first of DEFAULT_RENDERER_CLASSES
+// is used if none was, e.g. when the request is rejected before negotiation or because it failed.
+func respond(ctx *gin.Context, code int, data any) {
+	request := GetRequest(ctx)
+	renderer, mediaType := request.AcceptedRenderer, request.AcceptedMediaType
+	if renderer == nil {
+		renderer = JSONRenderer{}
+		if len(DEFAULT_RENDERER_CLASSES) > 0 {
+			renderer = DEFAULT_RENDERER_CLASSES[0]
+		}
+		mediaType = renderer.MediaTypes()[0]
+	}
+	if c, ok := renderer.(RendererCharset); ok && c.Charset() != "" {
+		mediaType += "; charset=" + c.Charset()
+	}
+	ctx.Render(code, rendered{
+		renderer:    renderer,
+		contentType: mediaType,
+		data:        data,
+	})
+}
@@ -0,0 +1,146 @@
+package django
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRenderers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	eng := gin.New()
+	Path(eng, "/books", NewHandler[bookSerializer]().Get(func(ctx *gin.Context, _ *bookSerializer) (int, any, error) {
+		return http.StatusOK, []bookSerializer{{ID: 1, Title: "dune"}}, nil
+	}), "")
+	Path(eng, "/json", NewHandler[bookSerializer](WithRenderers[bookSerializer](JSONRenderer{})).Get(func(ctx *gin.Context, _ *bookSerializer) (int, any, error) {
+		return http.StatusOK, nil, nil
+	}), "")
+
+	tests := []struct {
+		path, accept string
+		code         int
+		contentType  string
+		body         string
+	}{
+		{path: "/books", code: http.StatusOK, contentType: "application/json; charset=utf-8", body: `[{"id":1,"title":"dune"}]`},
+		{path: "/books", accept: "*/*", code: http.StatusOK, contentType: "application/json; charset=utf-8"},
+		{path: "/books", accept: "text/xml", code: http.StatusOK, contentType: "text/xml; charset=utf-8",
+			body: xmlHeader + `<root><list-item><id>1</id><title>dune</title></list-item></root>`},
+		{path: "/books", accept: "application/json;q=0.5, application/x-yaml", code: http.StatusOK, contentType: "application/x-yaml; charset=utf-8",
+			body: "- id: 1\n  title: dune\n"},
+		{path: "/books", accept: "application/*;q=0.9, application/x-msgpack", code: http.StatusOK, contentType: "application/x-msgpack"},
+		{path: "/books", accept: "application/json;q=0, */*;q=0.1", code: http.StatusOK, contentType: "application/xml; charset=utf-8"},
+		{path: "/books?format=yaml", accept: "application/json", code: http.StatusOK, contentType: "application/x-yaml; charset=utf-8"},
+		{path: "/books", accept: "text/html", code: http.StatusNotAcceptable, contentType: "application/json; charset=utf-8",
+			body: `{"detail":"could not satisfy the request Accept header","code":"not_acceptable"}`},
+		{path: "/json?format=xml", code: http.StatusNotAcceptable},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, test.path, nil)
+		if test.accept != "" {
+			r.Header.Set("Accept", test.accept)
+		}
+		eng.ServeHTTP(w, r)
+		if w.Code != test.code {
+			t.Errorf("%s %q: got %d, want %d", test.path, test.accept, w.Code, test.code)
+		}
+		if test.contentType != "" && w.Header().Get("Content-Type") != test.contentType {
+			t.Errorf("%s %q: got content type %q, want %q", test.path, test.accept, w.Header().Get("Content-Type"), test.contentType)
+		}
+		if test.body != "" && w.Body.String() != test.body {
+			t.Errorf("%s %q: got %q, want %q", test.path, test.accept, w.Body.String(), test.body)
+		}
+	}
+}
+
+const xmlHeader = `<?xml version="1.0" encoding="UTF-8"?>` + "\n"
+
+func TestYAMLRenderer(t *testing.T) {
+	type account struct {
+		Username  string    `json:"username"`
+		LastLogin string    `json:"last_login"`
+		Password  string    `json:"-"`
+		Groups    []string  `json:"groups"`
+		Owner     *APIError `json:"owner,omitempty"`
+		Score     float64   `json:"score"`
+	}
+	tests := []struct {
+		data any
+		want string
+	}{
+		{data: account{Username: "alice", LastLogin: "yes", Password: "secret", Groups: []string{}, Score: 1.5},
+			want: "username: alice\nlast_login: \"yes\"\ngroups: []\nscore: 1.5\n"},
+		{data: NewAPIError(http.StatusNotFound, "not found", "not_found"), want: "detail: not found\ncode: not_found\n"},
+		{data: map[string]any{"b": nil, "a": map[string]any{}}, want: "a: {}\nb: null\n"},
+	}
+	for _, test := range tests {
+		var b strings.Builder
+		if err := (YAMLRenderer{}).Render(&b, test.data); err != nil {
+			t.Errorf("%+v: got error %v", test.data, err)
+			continue
+		}
+		if b.String() != test.want {
+			t.Errorf("%+v: got %q, want %q", test.data, b.String(), test.want)
+		}
+	}
+}
+
+func TestXMLRenderer(t *testing.T) {
+	var b strings.Builder
+	if err := (XMLRenderer{}).Render(&b, map[string]int{"foo bar": 1, "1x": 2, "a<b": 3, "ok": 4}); err != nil {
+		t.Fatal(err)
+	}
+	want := xmlHeader + `<root><item key="1x">2</item><item key="a&lt;b">3</item><item key="foo bar">1</item><ok>4</ok></root>`
+	if b.String() != want {
+		t.Errorf("got %q, want %q", b.String(), want)
+	}
+	var keys map[string]int
+	if err := (XMLParser{}).ParseBody([]byte(b.String())).Decode(&keys); err != nil || !reflect.DeepEqual(keys, map[string]int{"foo bar": 1, "1x": 2, "a<b": 3, "ok": 4}) {
+		t.Errorf("parse: got %v, error %v", keys, err)
+	}
+
+	type author struct {
+		Name string `json:"name"`
+	}
+	type shelf struct {
+		author
+		ID       uint              `json:"id"`
+		Title    string            `json:"title"`
+		Tags     []string          `json:"tags"`
+		Ratings  map[string]int    `json:"ratings"`
+		Score    float64           `json:"score"`
+		Public   bool              `json:"public"`
+		Added    time.Time         `json:"added"`
+		Previous *shelf            `json:"previous,omitempty"`
+		Extra    map[string]string `json:"extra"`
+	}
+	data := shelf{
+		author:   author{Name: "herbert"},
+		ID:       2,
+		Title:    "dune",
+		Tags:     []string{"scifi", "classic"},
+		Ratings:  map[string]int{"five stars": 5},
+		Score:    4.5,
+		Public:   true,
+		Added:    time.Date(2022, 9, 1, 12, 0, 0, 0, time.UTC),
+		Previous: &shelf{ID: 1, Title: "messiah", Tags: []string{}, Ratings: map[string]int{}, Extra: map[string]string{}},
+		Extra:    map[string]string{},
+	}
+	b.Reset()
+	if err := (XMLRenderer{}).Render(&b, data); err != nil {
+		t.Fatal(err)
+	}
+	var parsed shelf
+	if err := (XMLParser{}).ParseBody([]byte(b.String())).Decode(&parsed); err != nil {
+		t.Fatalf("parse %s: %v", b.String(), err)
+	}
+	if !reflect.DeepEqual(parsed, data) {
+		t.Errorf("round trip of %s: got %+v, want %+v", b.String(), parsed, data)
+	}
+}
@@ -1,14 +1,159 @@
 package django
 
-import "net/http"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/alexedwards/scs/v2"
+	"github.com/gin-gonic/gin"
+)
+
+const requestKey = "django.request"
 
 type Request struct {
+	// User the authenticated user, AnonymousUser until an authenticator succeeds.
 	User           User
 	Authenticators []AuthenticationClass
-	Auth           string
+	Permissions    []PermissionClass
+	// Parsers the parsers the body of the request may be parsed with, chosen by its `Content-Type`.
+	Parsers []Parser
+	Auth    string
+	// Claims holds the claims of the token the request was authenticated with by JWTAuthentication.
+	Claims *JWTClaims
+	// AcceptedRenderer renders the response, in AcceptedMediaType, as negotiated with the client.
+	AcceptedRenderer  Renderer
+	AcceptedMediaType string
 	*http.Request
+
+	sessions   *scs.SessionManager
+	csrfExempt bool
+	clientIP   string
+	streamBody bool
+	bodyLimits []*limitedBody
+}
+
+// JSONStream returns a stream over the elements of the JSON array in the body of the request,
+// which must not have been parsed: the handler is configured with StreamBody.
+func (r *Request) JSONStream() (*JSONStream, error) {
+	if mt := mediaType(r.Request); mt != MIMEJSON {
+		return nil, unsupportedMediaType(mt)
+	}
+	return JSONParser{}.Stream(r.Request)
+}
+
+// parseError returns the error a request whose body failed to parse with err is answered with.
+func (r *Request) parseError(err error) *APIError {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	if r.bodyTooLarge() {
+		return requestTooLarge()
+	}
+	return NewAPIError(http.StatusBadRequest, "malformed request body: "+err.Error(), "parse_error")
+}
+
+// postFormValue returns the value of the field of a url encoded or multipart form body. the body is parsed
+// by the parser the handler parses it with, under its limits, which finds the parsed form when it runs.
+func (r *Request) postFormValue(key string) (string, *APIError) {
+	if mt := mediaType(r.Request); r.streamBody || !hasBody(r.Request) || (mt != MIMEPOSTForm && mt != MIMEMultipartPOSTForm) {
+		return "", nil
+	}
+	parser, apiErr := selectParser(r.Parsers, r.Request)
+	if apiErr != nil {
+		return "", nil
+	}
+	if _, err := parser.Parse(r.Request); err != nil {
+		return "", r.parseError(err)
+	}
+	return r.PostForm.Get(key), nil
+}
+
+// Session returns the session of the request, nil if the request did not go through SessionMiddleware.
+func (r *Request) Session() *Session {
+	if r.sessions == nil {
+		return nil
+	}
+	return &Session{
+		manager: r.sessions,
+		ctx:     r.Context(),
+	}
+}
+
+// GetRequest returns the Request of the context, creating it on first use.
+func GetRequest(ctx *gin.Context) *Request {
+	if r, ok := ctx.Get(requestKey); ok {
+		return r.(*Request)
+	}
+	r := &Request{
+		User:     AnonymousUser{},
+		Request:  ctx.Request,
+		clientIP: ctx.ClientIP(),
+	}
+	if manager, ok := ctx.Get(sessionManagerKey); ok {
+		r.sessions = manager.(*scs.SessionManager)
+	}
+	ctx.Set(requestKey, r)
+	return r
+}
+
+// authenticate tries each of the request's authenticators in order, setting User from the first that succeeds.
+// an authenticator failing with an APIError stops the search and the request is answered with it.
+func (r *Request) authenticate() error {
+	var err error
+	for _, authenticator := range r.Authenticators {
+		if err = authenticator.Authenticate(r); err == nil {
+			return nil
+		}
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			return err
+		}
+	}
+	if err == nil {
+		return fmt.Errorf("%w: no authentication classes", ErrorCouldNotAuthenticate)
+	}
+	if !errors.Is(err, ErrorCouldNotAuthenticate) {
+		err = fmt.Errorf("%w: %v", ErrorCouldNotAuthenticate, err)
+	}
+	return err
 }
 
-func (r *Request) Session() {
+// authenticateHeader returns the `WWW-Authenticate` header of the request's first authenticator.
+func (r *Request) authenticateHeader() string {
+	if len(r.Authenticators) == 0 {
+		return ""
+	}
+	return r.Authenticators[0].AuthenticateHeader()
+}
+
+// unauthenticatedStatus returns the status requests failing to authenticate are answered with: 401 when the
+// client can be challenged with a `WWW-Authenticate` header, 403 otherwise.
+func (r *Request) unauthenticatedStatus() int {
+	if r.authenticateHeader() == "" {
+		return http.StatusForbidden
+	}
+	return http.StatusUnauthorized
+}
+
+// checkPermissions returns the error the request is denied with by the first permission class refusing it.
+func (r *Request) checkPermissions() *APIError {
+	for _, permission := range r.Permissions {
+		if denied := deniedBy(permission, r); denied != nil {
+			return permissionDenied(r, denied)
+		}
+	}
+	return nil
+}
 
+// CheckObjectPermissions returns the error the request is denied with by the first permission class
+// refusing access to obj, or nil if access is granted.
+func (r *Request) CheckObjectPermissions(obj any) *APIError {
+	for _, permission := range r.Permissions {
+		if denied := objectDeniedBy(permission, r, obj); denied != nil {
+			return permissionDenied(r, denied)
+		}
+	}
+	return nil
 }
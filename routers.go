@@ -0,0 +1,108 @@
+package django
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TrailingSlash controls which variants of a path a Router publishes.
+type TrailingSlash int
+
+const (
+	// TrailingSlashAlways publishes `/users/` and `/users/:id/`.
+	TrailingSlashAlways TrailingSlash = iota
+	// TrailingSlashNever publishes `/users` and `/users/:id`.
+	TrailingSlashNever
+	// TrailingSlashOptional publishes both variants; the variant without the slash is the one reversed.
+	TrailingSlashOptional
+)
+
+// Router generates the routes of registered view sets, along with an API root listing every registered resource.
+type Router struct {
+	trailingSlash TrailingSlash
+	registry      []registration
+}
+
+type registration struct {
+	prefix   string
+	viewset  viewSet
+	basename string
+}
+
+func NewRouter(trailingSlash ...TrailingSlash) *Router {
+	r := Router{
+		trailingSlash: TrailingSlashAlways,
+	}
+	if len(trailingSlash) > 0 {
+		r.trailingSlash = trailingSlash[0]
+	}
+	return &r
+}
+
+// Register adds the view set under prefix. its routes are named `basename-list`, `basename-detail`
+// and `basename-<action>`.
+func (r *Router) Register(prefix string, viewset viewSet, basename string) *Router {
+	r.registry = append(r.registry, registration{
+		prefix:   strings.Trim(prefix, "/"),
+		viewset:  viewset,
+		basename: basename,
+	})
+	return r
+}
+
+// URLs returns a configuration holding the API root, named `api-root`, and the routes of every registered view set.
+func (r *Router) URLs() *URLConf {
+	conf := NewURLConf()
+	rootPath := "/"
+	if r.trailingSlash == TrailingSlashNever {
+		rootPath = ""
+	}
+	conf.Path(rootPath, apiRoot{router: r}, "api-root")
+
+	for _, reg := range r.registry {
+		for _, route := range reg.viewset.routes("/"+reg.prefix, reg.basename) {
+			switch r.trailingSlash {
+			case TrailingSlashAlways:
+				route.path += "/"
+				conf.entries = append(conf.entries, urlEntry{route: route})
+			case TrailingSlashNever:
+				conf.entries = append(conf.entries, urlEntry{route: route})
+			case TrailingSlashOptional:
+				conf.entries = append(conf.entries, urlEntry{route: route}, urlEntry{route: newRoute(route.path+"/", route.view)})
+			}
+		}
+	}
+	return conf
+}
+
+// apiRoot lists the url of every resource registered on a router.
+type apiRoot struct {
+	router *Router
+}
+
+func (v apiRoot) asView(router *gin.RouterGroup) {
+	router.GET("", v.get)
+}
+
+func (v apiRoot) get(ctx *gin.Context) {
+	if err := GetRequest(ctx).negotiate(DEFAULT_RENDERER_CLASSES); err != nil {
+		abort(ctx, err)
+		return
+	}
+	scheme := "http"
+	if ctx.Request.TLS != nil {
+		scheme = "https"
+	}
+	base := strings.TrimSuffix(ctx.FullPath(), "/")
+	resources := make(map[string]string, len(v.router.registry))
+	for _, reg := range v.router.registry {
+		url := scheme + "://" + ctx.Request.Host + base + "/" + reg.prefix
+		if v.router.trailingSlash == TrailingSlashAlways {
+			url += "/"
+		}
+		resources[reg.basename] = url
+	}
+	respond(ctx, http.StatusOK, resources)
+}
@@ -0,0 +1,99 @@
+package django
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRouter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	viewset := NewViewSet[bookSerializer]().
+		List(func(ctx *gin.Context, _ *bookSerializer) (int, any, error) {
+			return http.StatusOK, "list", nil
+		}).
+		Retrieve(func(ctx *gin.Context, _ *bookSerializer) (int, any, error) {
+			return http.StatusOK, "retrieve", nil
+		})
+
+	tests := []struct {
+		namespace     string
+		trailingSlash TrailingSlash
+		found         []string
+		notFound      []string
+		reversed      map[string]string
+		root          map[string]string
+	}{
+		{
+			namespace:     "always",
+			trailingSlash: TrailingSlashAlways,
+			found:         []string{"/always/", "/always/users/", "/always/users/1/"},
+			notFound:      []string{"/always/users", "/always/users/1"},
+			reversed:      map[string]string{"api-root": "/always/", "user-list": "/always/users/", "user-detail": "/always/users/1/"},
+			root:          map[string]string{"user": "http://example.com/always/users/"},
+		},
+		{
+			namespace:     "never",
+			trailingSlash: TrailingSlashNever,
+			found:         []string{"/never", "/never/users", "/never/users/1"},
+			notFound:      []string{"/never/users/", "/never/users/1/"},
+			reversed:      map[string]string{"api-root": "/never", "user-list": "/never/users", "user-detail": "/never/users/1"},
+			root:          map[string]string{"user": "http://example.com/never/users"},
+		},
+		{
+			namespace:     "optional",
+			trailingSlash: TrailingSlashOptional,
+			found:         []string{"/optional/", "/optional/users", "/optional/users/", "/optional/users/1", "/optional/users/1/"},
+			reversed:      map[string]string{"api-root": "/optional/", "user-list": "/optional/users", "user-detail": "/optional/users/1"},
+			root:          map[string]string{"user": "http://example.com/optional/users"},
+		},
+	}
+	for _, test := range tests {
+		router := NewRouter(test.trailingSlash).Register("/users/", viewset, "user")
+		conf := NewURLConf().Include("/"+test.namespace, router.URLs(), test.namespace)
+		eng := gin.New()
+		eng.RedirectTrailingSlash = false
+		conf.Register(eng)
+
+		for _, path := range test.found {
+			w := httptest.NewRecorder()
+			eng.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+			if w.Code != http.StatusOK {
+				t.Errorf("%s: GET %s got status %d, want %d", test.namespace, path, w.Code, http.StatusOK)
+			}
+		}
+		for _, path := range test.notFound {
+			w := httptest.NewRecorder()
+			eng.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+			if w.Code != http.StatusNotFound {
+				t.Errorf("%s: GET %s got status %d, want %d", test.namespace, path, w.Code, http.StatusNotFound)
+			}
+		}
+		for name, want := range test.reversed {
+			got, err := Reverse(test.namespace+":"+name, map[string]any{"id": 1})
+			if err != nil {
+				t.Errorf("%s: Reverse(%q) returned error %v", test.namespace, name, err)
+				continue
+			}
+			if got != want {
+				t.Errorf("%s: Reverse(%q) got %s, want %s", test.namespace, name, got, want)
+			}
+		}
+
+		root, _ := Reverse(test.namespace+":api-root", nil)
+		w := httptest.NewRecorder()
+		eng.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "http://example.com"+root, nil))
+		var got map[string]string
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+			t.Errorf("%s: GET %s returned invalid json: %v", test.namespace, root, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.root) {
+			t.Errorf("%s: GET %s listed %v, want %v", test.namespace, root, got, test.root)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package django
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/alexedwards/scs/v2"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	sessionManagerKey = "django.sessions"
+	sessionUserKey    = "userID"
+)
+
+// SessionMiddleware loads the session of each request from the session cookie, and saves it,
+// writing the cookie, before the response is sent. it must run before any handler using sessions.
+func SessionMiddleware(manager *scs.SessionManager) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var token string
+		if cookie, err := ctx.Request.Cookie(manager.Cookie.Name); err == nil {
+			token = cookie.Value
+		}
+		sessionCtx, err := manager.Load(ctx.Request.Context(), token)
+		if err != nil {
+			_ = ctx.Error(err)
+			abort(ctx, ErrInternal())
+			return
+		}
+		ctx.Request = ctx.Request.WithContext(sessionCtx)
+		ctx.Set(sessionManagerKey, manager)
+
+		writer := &sessionWriter{
+			ResponseWriter: ctx.Writer,
+			ctx:            ctx,
+			manager:        manager,
+		}
+		ctx.Writer = writer
+		ctx.Next()
+		writer.commit()
+	}
+}
+
+// sessionWriter saves the session before the response headers are written.
+type sessionWriter struct {
+	gin.ResponseWriter
+	ctx       *gin.Context
+	manager   *scs.SessionManager
+	committed bool
+}
+
+func (w *sessionWriter) commit() {
+	if w.committed {
+		return
+	}
+	w.committed = true
+	ctx := w.ctx.Request.Context()
+	switch w.manager.Status(ctx) {
+	case scs.Modified:
+		token, expiry, err := w.manager.Commit(ctx)
+		if err != nil {
+			_ = w.ctx.Error(err)
+			return
+		}
+		w.manager.WriteSessionCookie(ctx, w.ResponseWriter, token, expiry)
+	case scs.Destroyed:
+		w.manager.WriteSessionCookie(ctx, w.ResponseWriter, "", time.Time{})
+	}
+	w.Header().Add("Vary", "Cookie")
+}
+
+func (w *sessionWriter) WriteHeaderNow() {
+	w.commit()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *sessionWriter) Write(data []byte) (int, error) {
+	w.commit()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *sessionWriter) WriteString(s string) (int, error) {
+	w.commit()
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Session the session of a request.
+type Session struct {
+	manager *scs.SessionManager
+	ctx     context.Context
+}
+
+func (s *Session) Get(key string) any {
+	return s.manager.Get(s.ctx, key)
+}
+
+func (s *Session) Put(key string, value any) {
+	s.manager.Put(s.ctx, key, value)
+}
+
+func (s *Session) Pop(key string) any {
+	return s.manager.Pop(s.ctx, key)
+}
+
+func (s *Session) Remove(key string) {
+	s.manager.Remove(s.ctx, key)
+}
+
+func (s *Session) Exists(key string) bool {
+	return s.manager.Exists(s.ctx, key)
+}
+
+func (s *Session) Token() string {
+	return s.manager.Token(s.ctx)
+}
+
+// RenewToken changes the session token while keeping its data, preventing session fixation.
+func (s *Session) RenewToken() error {
+	return s.manager.RenewToken(s.ctx)
+}
+
+// Destroy deletes the session data and expires the session cookie.
+func (s *Session) Destroy() error {
+	return s.manager.Destroy(s.ctx)
+}
+
+// LoginSerializer the credentials a session is opened with.
+type LoginSerializer struct {
+	Username string `json:"username" form:"username"`
+	Password string `json:"password" form:"password"`
+}
+
+func (LoginSerializer) Metadata() []Field {
+	return []Field{
+		{Name: "username", Required: true},
+		{Name: "password", Required: true, WriteOnly: true},
+	}
+}
+
+type LogoutSerializer struct{}
+
+func (LogoutSerializer) Metadata() []Field {
+	return nil
+}
+
+// LoginHandler a handler logging users in on POST: the credentials are validated through the provider,
+// the session token is renewed and the id of the user is stored in the session read by SessionAuthentication.
+func LoginHandler(provider UserProvider, opts ...Opt[LoginSerializer]) *Handler[LoginSerializer] {
+	return NewHandler[LoginSerializer](opts...).Post(func(ctx *gin.Context, credentials *LoginSerializer) (int, any, error) {
+		session := GetRequest(ctx).Session()
+		if session == nil {
+			return http.StatusInternalServerError, ErrInternal(), ErrorNoSession
+		}
+		denied := NewAPIError(http.StatusBadRequest, "unable to log in with the provided credentials", "invalid_credentials")
+		u, err := provider.FindUser(credentials.Username)
+		if err != nil {
+			return denied.Status, denied, err
+		}
+		if err = u.ValidatePassword(credentials.Password); err != nil {
+			return denied.Status, denied, err
+		}
+		id, ok := userID(u)
+		if !ok {
+			return http.StatusInternalServerError, ErrInternal(), ErrorNoUserID
+		}
+		if err = session.RenewToken(); err != nil {
+			return http.StatusInternalServerError, ErrInternal(), err
+		}
+		session.Put(sessionUserKey, id)
+		// the CSRF token is rotated on login, EnsureCSRFCookie hands out the new one.
+		session.Remove(csrfSessionKey)
+		return http.StatusOK, map[string]any{"id": id, "username": u.Username()}, nil
+	})
+}
+
+// LogoutHandler a handler destroying the session of the request on POST.
+func LogoutHandler(opts ...Opt[LogoutSerializer]) *Handler[LogoutSerializer] {
+	return NewHandler[LogoutSerializer](opts...).Post(func(ctx *gin.Context, _ *LogoutSerializer) (int, any, error) {
+		session := GetRequest(ctx).Session()
+		if session == nil {
+			return http.StatusInternalServerError, ErrInternal(), ErrorNoSession
+		}
+		if err := session.Destroy(); err != nil {
+			return http.StatusInternalServerError, ErrInternal(), err
+		}
+		return http.StatusNoContent, nil, nil
+	})
+}
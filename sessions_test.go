@@ -0,0 +1,89 @@
+package django
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alexedwards/scs/v2"
+	"github.com/gin-gonic/gin"
+)
+
+func TestSessions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	provider := testUserProvider{1: {id: 1, username: "alice", password: "secret"}}
+	manager := scs.New()
+	eng := gin.New()
+	eng.Use(SessionMiddleware(manager))
+	Path(eng, "/csrf", NewHandler[bookSerializer]().Get(func(ctx *gin.Context, _ *bookSerializer) (int, any, error) {
+		token, err := CSRFToken(ctx)
+		return http.StatusOK, token, err
+	}), "")
+	Path(eng, "/login", LoginHandler(provider), "")
+	Path(eng, "/logout", LogoutHandler(), "")
+	Path(eng, "/me", NewHandler[bookSerializer](
+		WithAuthentication[bookSerializer](NewSessionAuthentication(provider, manager)),
+	).Get(func(ctx *gin.Context, _ *bookSerializer) (int, any, error) {
+		return http.StatusOK, GetRequest(ctx).User.Username(), nil
+	}), "")
+
+	serve := func(method, path, body, session string) (*httptest.ResponseRecorder, *http.Cookie) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		if body != "" {
+			r.Header.Set("Content-Type", MIMEJSON)
+		}
+		if session != "" {
+			r.AddCookie(&http.Cookie{Name: manager.Cookie.Name, Value: session})
+		}
+		eng.ServeHTTP(w, r)
+		for _, cookie := range w.Result().Cookies() {
+			if cookie.Name == manager.Cookie.Name {
+				return w, cookie
+			}
+		}
+		return w, nil
+	}
+
+	w, cookie := serve(http.MethodGet, "/csrf", "", "")
+	if w.Code != http.StatusOK || cookie == nil {
+		t.Fatalf("GET /csrf got status %d and session cookie %v, want %d and a cookie", w.Code, cookie, http.StatusOK)
+	}
+	anonymous, csrfToken := cookie.Value, w.Body.String()
+
+	w, cookie = serve(http.MethodPost, "/login", `{"username":"alice","password":"wrong"}`, anonymous)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("login with a wrong password got status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+
+	w, cookie = serve(http.MethodPost, "/login", `{"username":"alice","password":"secret"}`, anonymous)
+	if w.Code != http.StatusOK || cookie == nil {
+		t.Fatalf("login got status %d and session cookie %v, want %d and a cookie", w.Code, cookie, http.StatusOK)
+	}
+	if cookie.Value == anonymous {
+		t.Errorf("login kept the session token %s, want it renewed", anonymous)
+	}
+	authenticated := cookie.Value
+
+	if w, _ = serve(http.MethodGet, "/me", "", authenticated); w.Code != http.StatusOK || w.Body.String() != `"alice"` {
+		t.Errorf("GET /me got %d %s, want %d %s", w.Code, w.Body.String(), http.StatusOK, `"alice"`)
+	}
+	if w, _ = serve(http.MethodGet, "/me", "", anonymous); w.Code != http.StatusForbidden {
+		t.Errorf("GET /me with the token from before login got status %d, want %d", w.Code, http.StatusForbidden)
+	}
+	if w, _ = serve(http.MethodGet, "/csrf", "", authenticated); w.Body.String() == csrfToken {
+		t.Errorf("login kept the CSRF token %s, want it rotated", csrfToken)
+	}
+
+	w, cookie = serve(http.MethodPost, "/logout", "", authenticated)
+	if w.Code != http.StatusNoContent {
+		t.Errorf("logout got status %d, want %d", w.Code, http.StatusNoContent)
+	}
+	if cookie == nil || cookie.Value != "" || cookie.MaxAge >= 0 {
+		t.Errorf("logout got session cookie %v, want it cleared", cookie)
+	}
+	if w, _ = serve(http.MethodGet, "/me", "", authenticated); w.Code != http.StatusForbidden {
+		t.Errorf("GET /me after logout got status %d, want %d", w.Code, http.StatusForbidden)
+	}
+}
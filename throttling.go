@@ -0,0 +1,225 @@
+package django
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// DEFAULT_THROTTLE_RATES the rates of throttle scopes, e.g. "anon": "100/day", "uploads": "10/min".
+	// throttles of scopes without a rate do not limit requests.
+	DEFAULT_THROTTLE_RATES = map[string]string{}
+	// DEFAULT_THROTTLE_CLASSES the throttles used by WithThrottles when no throttles are given.
+	DEFAULT_THROTTLE_CLASSES = []Throttle{}
+	// DEFAULT_THROTTLE_STORE the store throttles keep request histories in unless given another with WithThrottleStore.
+	DEFAULT_THROTTLE_STORE ThrottleStore = NewMemoryThrottleStore()
+)
+
+// Throttle limits the rate of requests.
+type Throttle interface {
+	// AllowRequest returns true if the request may proceed, otherwise how long the client should wait
+	// before its next request is allowed.
+	AllowRequest(request *Request) (bool, time.Duration, error)
+}
+
+// ThrottleStore keeps the request history of throttled clients.
+// implement it on a shared backend to throttle clients across instances.
+type ThrottleStore interface {
+	// Hit records a request of key at now if fewer than limit requests of key were recorded in the window before now.
+	// if the request is not recorded, it returns how long until it would be.
+	Hit(ctx context.Context, key string, limit int, window time.Duration, now time.Time) (bool, time.Duration, error)
+}
+
+// MemoryThrottleStore a ThrottleStore keeping a sliding window of request times per key in memory.
+type MemoryThrottleStore struct {
+	mu      sync.Mutex
+	windows map[string]*throttleWindow
+	hits    int
+}
+
+type throttleWindow struct {
+	hits   []time.Time
+	window time.Duration
+}
+
+// memoryThrottleSweep the number of hits after which expired windows are dropped from a MemoryThrottleStore.
+const memoryThrottleSweep = 1000
+
+func NewMemoryThrottleStore() *MemoryThrottleStore {
+	return &MemoryThrottleStore{
+		windows: make(map[string]*throttleWindow),
+	}
+}
+
+func (s *MemoryThrottleStore) Hit(_ context.Context, key string, limit int, window time.Duration, now time.Time) (bool, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.hits++
+	if s.hits >= memoryThrottleSweep {
+		s.hits = 0
+		for k, w := range s.windows {
+			if len(w.hits) == 0 || !w.hits[len(w.hits)-1].Add(w.window).After(now) {
+				delete(s.windows, k)
+			}
+		}
+	}
+
+	w, ok := s.windows[key]
+	if !ok {
+		w = &throttleWindow{}
+		s.windows[key] = w
+	}
+	w.window = window
+	expired := 0
+	for expired < len(w.hits) && !w.hits[expired].Add(window).After(now) {
+		expired++
+	}
+	w.hits = w.hits[expired:]
+	if len(w.hits) >= limit {
+		if len(w.hits) == 0 {
+			return false, window, nil
+		}
+		return false, w.hits[len(w.hits)-limit].Add(window).Sub(now), nil
+	}
+	w.hits = append(w.hits, now)
+	return true, 0, nil
+}
+
+// parseRate parses a rate of the form "<requests>/<period>", where the period is second, minute, hour or day
+// and only its first letter is significant, e.g. "10/min" or "1000/day".
+func parseRate(rate string) (int, time.Duration, error) {
+	num, period, ok := strings.Cut(rate, "/")
+	if !ok || period == "" {
+		return 0, 0, fmt.Errorf("invalid throttle rate %q", rate)
+	}
+	limit, err := strconv.Atoi(num)
+	if err != nil || limit < 0 {
+		return 0, 0, fmt.Errorf("invalid throttle rate %q", rate)
+	}
+	durations := map[byte]time.Duration{
+		's': time.Second,
+		'm': time.Minute,
+		'h': time.Hour,
+		'd': 24 * time.Hour,
+	}
+	window, ok := durations[period[0]]
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid throttle rate %q: unknown period %q", rate, period)
+	}
+	return limit, window, nil
+}
+
+type ThrottleOpt func(*RateThrottle)
+
+// WithThrottleRate sets the rate of the throttle, instead of the rate of its scope in DEFAULT_THROTTLE_RATES.
+func WithThrottleRate(rate string) ThrottleOpt {
+	return func(t *RateThrottle) {
+		t.rate = rate
+	}
+}
+
+// WithThrottleStore keeps the request histories of the throttle in store instead of DEFAULT_THROTTLE_STORE.
+func WithThrottleStore(store ThrottleStore) ThrottleOpt {
+	return func(t *RateThrottle) {
+		t.store = store
+	}
+}
+
+// RateThrottle limits the requests of each client to the rate of its scope.
+type RateThrottle struct {
+	scope string
+	rate  string
+	store ThrottleStore
+	// ident returns the key identifying the client of the request, false if the request is not throttled.
+	ident func(request *Request) (string, bool)
+	now   func() time.Time
+}
+
+func newRateThrottle(scope string, ident func(request *Request) (string, bool), opts ...ThrottleOpt) *RateThrottle {
+	t := RateThrottle{
+		scope: scope,
+		ident: ident,
+		now:   time.Now,
+	}
+	for _, opt := range opts {
+		opt(&t)
+	}
+	return &t
+}
+
+// NewAnonRateThrottle throttles unauthenticated requests by client IP, at the rate of the "anon" scope.
+func NewAnonRateThrottle(opts ...ThrottleOpt) *RateThrottle {
+	return newRateThrottle("anon", func(request *Request) (string, bool) {
+		if request.User.IsAuthenticated() {
+			return "", false
+		}
+		return "throttle_anon_" + request.clientIP, true
+	}, opts...)
+}
+
+// NewUserRateThrottle throttles authenticated requests by user and other requests by client IP,
+// at the rate of the "user" scope.
+func NewUserRateThrottle(opts ...ThrottleOpt) *RateThrottle {
+	return newRateThrottle("user", func(request *Request) (string, bool) {
+		return "throttle_user_" + clientIdent(request), true
+	}, opts...)
+}
+
+// NewScopedRateThrottle throttles the requests of each user, or client IP if not authenticated,
+// at the rate of the scope. throttles of the same scope share their limit across handlers.
+func NewScopedRateThrottle(scope string, opts ...ThrottleOpt) *RateThrottle {
+	return newRateThrottle(scope, func(request *Request) (string, bool) {
+		return "throttle_" + scope + "_" + clientIdent(request), true
+	}, opts...)
+}
+
+// clientIdent identifies authenticated requests by user id, or username if the user has no id, and others by client IP.
+func clientIdent(request *Request) string {
+	if request.User.IsAuthenticated() {
+		if id, ok := userID(request.User); ok {
+			return "user:" + strconv.FormatUint(uint64(id), 10)
+		}
+		return "username:" + request.User.Username()
+	}
+	return "ip:" + request.clientIP
+}
+
+func (t *RateThrottle) AllowRequest(request *Request) (bool, time.Duration, error) {
+	rate := t.rate
+	if rate == "" {
+		rate = DEFAULT_THROTTLE_RATES[t.scope]
+	}
+	if rate == "" {
+		return true, 0, nil
+	}
+	key, ok := t.ident(request)
+	if !ok {
+		return true, 0, nil
+	}
+	limit, window, err := parseRate(rate)
+	if err != nil {
+		return true, 0, err
+	}
+	store := t.store
+	if store == nil {
+		store = DEFAULT_THROTTLE_STORE
+	}
+	return store.Hit(request.Context(), key, limit, window, t.now())
+}
+
+// retryAfter rounds the wait up to whole seconds, as sent in the `Retry-After` header.
+func retryAfter(wait time.Duration) int {
+	return int(math.Ceil(wait.Seconds()))
+}
+
+// throttledError returns the error throttled requests are answered with.
+func throttledError(wait time.Duration) *APIError {
+	return NewAPIError(http.StatusTooManyRequests, fmt.Sprintf("request was throttled, expected available in %d seconds", retryAfter(wait)), "throttled")
+}
@@ -0,0 +1,70 @@
+package django
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMemoryThrottleStore(t *testing.T) {
+	store := NewMemoryThrottleStore()
+	start := time.Date(2022, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		offset  time.Duration
+		allowed bool
+		wait    time.Duration
+	}{
+		{offset: 0, allowed: true},
+		{offset: 20 * time.Second, allowed: true},
+		{offset: 30 * time.Second, allowed: false, wait: 30 * time.Second},
+		{offset: 60 * time.Second, allowed: true},
+		{offset: 70 * time.Second, allowed: false, wait: 10 * time.Second},
+		{offset: 80 * time.Second, allowed: true},
+	}
+	for _, test := range tests {
+		allowed, wait, err := store.Hit(context.Background(), "key", 2, time.Minute, start.Add(test.offset))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if allowed != test.allowed || wait != test.wait {
+			t.Errorf("at %s: got %t %s, want %t %s", test.offset, allowed, wait, test.allowed, test.wait)
+		}
+	}
+}
+
+func TestThrottles(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	defer func(rates map[string]string) { DEFAULT_THROTTLE_RATES = rates }(DEFAULT_THROTTLE_RATES)
+	DEFAULT_THROTTLE_RATES = map[string]string{"anon": "2/min", "uploads": "1/hour"}
+
+	store := NewMemoryThrottleStore()
+	handler := func(ctx *gin.Context, _ *bookSerializer) (int, any, error) {
+		return http.StatusOK, nil, nil
+	}
+	eng := gin.New()
+	Path(eng, "/books", NewHandler[bookSerializer](WithThrottles[bookSerializer](
+		NewAnonRateThrottle(WithThrottleStore(store)),
+		NewScopedRateThrottle("uploads", WithThrottleStore(store)),
+	)).Get(handler), "")
+
+	tests := []struct {
+		code       int
+		retryAfter string
+	}{
+		{code: http.StatusOK},
+		{code: http.StatusTooManyRequests, retryAfter: "3600"},
+		{code: http.StatusTooManyRequests, retryAfter: "3600"},
+	}
+	for i, test := range tests {
+		w := httptest.NewRecorder()
+		eng.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/books", nil))
+		if w.Code != test.code || w.Header().Get("Retry-After") != test.retryAfter {
+			t.Errorf("request %d: got %d retry after %q, want %d %q", i, w.Code, w.Header().Get("Retry-After"), test.code, test.retryAfter)
+		}
+	}
+}
@@ -1,11 +1,195 @@
 package django
 
-import "github.com/gin-gonic/gin"
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
 
 type view interface {
 	asView(router *gin.RouterGroup)
 }
 
-func Path(eng *gin.Engine, path string, view view) {
+// Route a view mounted at a path.
+// middleware given to Path or attached with Use is part of the route's handler chain, running in the order it was added
+// before the view's handlers. a middleware that aborts the context prevents the view from running.
+type Route struct {
+	path       string
+	name       string
+	view       view
+	middleware []gin.HandlerFunc
+	mounted    bool
+}
+
+// Use attaches middleware to the route. the route must not be mounted yet: middleware of the routes created by Path
+// is given to Path, middleware is attached to the routes of a URLConf before it is registered.
+func (r *Route) Use(middleware ...gin.HandlerFunc) *Route {
+	if r.mounted {
+		panic(fmt.Sprintf("django: middleware attached to route %q after it was mounted", r.path))
+	}
+	r.middleware = append(r.middleware, middleware...)
+	return r
+}
+
+func (r *Route) Path() string {
+	return r.path
+}
+
+func (r *Route) Name() string {
+	return r.name
+}
+
+func (r *Route) handle(p *pattern) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		params := make(map[string]string, len(ctx.Params))
+		for _, param := range ctx.Params {
+			params[param.Key] = param.Value
+		}
+		if !p.validate(params) {
+			abort(ctx, ErrNotFound())
+			return
+		}
+		for i := range ctx.Params {
+			ctx.Params[i].Value = params[ctx.Params[i].Key]
+		}
+		ctx.Next()
+	}
+}
+
+// mount registers the view on the engine under prefix. a named route is registered
+// for reversal as `namespace:name` when a namespace is given.
+func (r *Route) mount(eng *gin.Engine, prefix, namespace string) {
+	p := parsePattern(joinPaths(prefix, r.path))
+	group := eng.Group(p.route())
+	group.Use(r.handle(p))
+	group.Use(r.middleware...)
+	r.view.asView(group)
+	r.mounted = true
+	if r.name != "" {
+		registerName(joinNamespace(namespace, r.name), p)
+	}
+}
+
+// Path mounts the view at path on the engine and returns the created Route.
+// path segments may use converters, e.g. `/articles/<int:year>/<slug:title>`; requests whose
+// parameters do not match a converter are answered with 404 before the view runs.
+// a route given a name can be reversed with Reverse, an empty name leaves the route unnamed. middleware runs,
+// in order, before the view's handlers; the route is mounted immediately so it is given here rather than with Use.
+func Path(eng *gin.Engine, path string, view view, name string, middleware ...gin.HandlerFunc) *Route {
+	r := newRoute(path, view, name)
+	r.Use(middleware...)
+	r.mount(eng, "", "")
+	return r
+}
+
+func newRoute(path string, view view, name ...string) *Route {
+	r := &Route{
+		path: path,
+		view: view,
+	}
+	if len(name) > 0 {
+		r.name = name[0]
+	}
+	return r
+}
+
+// URLConf a collection of routes that is registered on an engine in one call.
+// configurations can be nested under a prefix and namespace with Include.
+type URLConf struct {
+	entries []urlEntry
+}
+
+// urlEntry either a route or an included configuration.
+type urlEntry struct {
+	route     *Route
+	prefix    string
+	namespace string
+	include   *URLConf
+}
+
+func NewURLConf() *URLConf {
+	return new(URLConf)
+}
+
+// Path adds the view at path to the configuration and returns the created Route.
+// the route is not mounted until the configuration is registered.
+func (c *URLConf) Path(path string, view view, name ...string) *Route {
+	r := newRoute(path, view, name...)
+	c.entries = append(c.entries, urlEntry{route: r})
+	return r
+}
+
+// Include nests conf under prefix. names of routes in conf are reversed as `namespace:name`
+// when a namespace is given.
+func (c *URLConf) Include(prefix string, conf *URLConf, namespace ...string) *URLConf {
+	entry := urlEntry{
+		prefix:  prefix,
+		include: conf,
+	}
+	if len(namespace) > 0 {
+		entry.namespace = namespace[0]
+	}
+	c.entries = append(c.entries, entry)
+	return c
+}
+
+// Register mounts every route of the configuration, and of the configurations it includes, on the engine.
+func (c *URLConf) Register(eng *gin.Engine) {
+	c.register(eng, "", "")
+}
+
+func (c *URLConf) register(eng *gin.Engine, prefix, namespace string) {
+	for _, entry := range c.entries {
+		if entry.route != nil {
+			entry.route.mount(eng, prefix, namespace)
+			continue
+		}
+		entry.include.register(eng, joinPaths(prefix, entry.prefix), joinNamespace(namespace, entry.namespace))
+	}
+}
+
+func joinPaths(prefix, path string) string {
+	if path == "" {
+		return prefix
+	}
+	joined := strings.TrimSuffix(prefix, "/") + "/" + strings.TrimPrefix(path, "/")
+	if !strings.HasPrefix(joined, "/") {
+		joined = "/" + joined
+	}
+	return joined
+}
+
+func joinNamespace(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+	if name == "" {
+		return namespace
+	}
+	return namespace + ":" + name
+}
+
+var (
+	namesMu sync.RWMutex
+	names   = make(map[string]*pattern)
+)
+
+func registerName(name string, p *pattern) {
+	namesMu.Lock()
+	defer namesMu.Unlock()
+	names[name] = p
+}
 
+// Reverse builds the path of the route registered under name, filling its parameters from params.
+// routes included under a namespace are named `namespace:name`, e.g. `billing:invoice-detail`.
+func Reverse(name string, params map[string]any) (string, error) {
+	namesMu.RLock()
+	p, ok := names[name]
+	namesMu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("%w: no route named %q", ErrorNoReverseMatch, name)
+	}
+	return p.reverse(params)
 }
@@ -0,0 +1,184 @@
+package django
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type articleSerializer struct {
+	Year  int    `uri:"year" json:"year"`
+	Title string `uri:"title" json:"title"`
+}
+
+func (articleSerializer) Metadata() []Field {
+	return []Field{{Name: "year"}, {Name: "title"}}
+}
+
+func TestPath(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	eng := gin.New()
+	handler := NewHandler[articleSerializer]().Get(func(ctx *gin.Context, s *articleSerializer) (int, any, error) {
+		return http.StatusOK, s, nil
+	})
+	Path(eng, "/articles/<int:year>/<slug:title>", handler, "article-detail")
+
+	tests := []struct {
+		path string
+		code int
+		want articleSerializer
+	}{
+		{path: "/articles/2022/hello-world", code: http.StatusOK, want: articleSerializer{Year: 2022, Title: "hello-world"}},
+		{path: "/articles/twenty/hello-world", code: http.StatusNotFound},
+		{path: "/articles/2022/hello%20world", code: http.StatusNotFound},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		eng.ServeHTTP(w, httptest.NewRequest(http.MethodGet, test.path, nil))
+		if w.Code != test.code {
+			t.Errorf("GET %s got status %d, want %d", test.path, w.Code, test.code)
+			continue
+		}
+		if test.code != http.StatusOK {
+			continue
+		}
+		var got articleSerializer
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+			t.Errorf("GET %s returned invalid json: %v", test.path, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("GET %s bound %+v, want %+v", test.path, got, test.want)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	eng.ServeHTTP(w, httptest.NewRequest(http.MethodOptions, "/articles/2022/hello-world", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("OPTIONS got status %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestReverse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	eng := gin.New()
+	Path(eng, "/users/<int:id>/", NewHandler[articleSerializer](), "user-detail")
+
+	got, err := Reverse("user-detail", map[string]any{"id": 42})
+	if err != nil {
+		t.Fatalf("Reverse returned error %v", err)
+	}
+	if got != "/users/42/" {
+		t.Errorf("Reverse got %s, want %s", got, "/users/42/")
+	}
+
+	// reserved characters are escaped, the slashes of path values kept.
+	Path(eng, "/tags/<str:tag>/", NewHandler[articleSerializer](), "tag-detail")
+	Path(eng, "/files/<path:name>", NewHandler[articleSerializer](), "file-detail")
+	for _, test := range []struct {
+		name  string
+		param string
+		value string
+		want  string
+	}{
+		{name: "tag-detail", param: "tag", value: "a b?c#d", want: "/tags/a%20b%3Fc%23d/"},
+		{name: "file-detail", param: "name", value: "docs/a b/c#1", want: "/files/docs/a%20b/c%231"},
+	} {
+		if got, err = Reverse(test.name, map[string]any{test.param: test.value}); err != nil || got != test.want {
+			t.Errorf("Reverse %s %q got %s, error %v, want %s", test.name, test.value, got, err, test.want)
+		}
+	}
+
+	if _, err = Reverse("user-detail", map[string]any{"id": "abc"}); !errors.Is(err, ErrorNoReverseMatch) {
+		t.Errorf("Reverse with an invalid value got error %v, want %v", err, ErrorNoReverseMatch)
+	}
+	if _, err = Reverse("user-detail", nil); !errors.Is(err, ErrorNoReverseMatch) {
+		t.Errorf("Reverse with a missing value got error %v, want %v", err, ErrorNoReverseMatch)
+	}
+	if _, err = Reverse("missing", nil); !errors.Is(err, ErrorNoReverseMatch) {
+		t.Errorf("Reverse of an unknown name got error %v, want %v", err, ErrorNoReverseMatch)
+	}
+}
+
+func TestURLConfInclude(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewHandler[articleSerializer]().Get(func(ctx *gin.Context, s *articleSerializer) (int, any, error) {
+		return http.StatusOK, s, nil
+	})
+
+	invoices := NewURLConf()
+	invoices.Path("/invoices/<int:year>/", handler, "invoice-detail")
+	billing := NewURLConf()
+	billing.Include("/billing", invoices, "billing")
+	root := NewURLConf()
+	root.Include("/api/v1", billing, "v1")
+
+	eng := gin.New()
+	root.Register(eng)
+
+	got, err := Reverse("v1:billing:invoice-detail", map[string]any{"year": 2022})
+	if err != nil {
+		t.Fatalf("Reverse returned error %v", err)
+	}
+	if want := "/api/v1/billing/invoices/2022/"; got != want {
+		t.Errorf("Reverse got %s, want %s", got, want)
+	}
+
+	w := httptest.NewRecorder()
+	eng.ServeHTTP(w, httptest.NewRequest(http.MethodGet, got, nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("GET %s got status %d, want %d", got, w.Code, http.StatusOK)
+	}
+}
+
+func TestRouteUse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	eng := gin.New()
+	var order []string
+	handler := NewHandler[articleSerializer]().Get(func(ctx *gin.Context, s *articleSerializer) (int, any, error) {
+		order = append(order, "view")
+		return http.StatusOK, s, nil
+	})
+	logger := func(ctx *gin.Context) {
+		order = append(order, "logger")
+		ctx.Next()
+		order = append(order, "logger done")
+	}
+	deny := func(ctx *gin.Context) {
+		order = append(order, "deny")
+		ctx.AbortWithStatus(http.StatusForbidden)
+	}
+	conf := NewURLConf()
+	conf.Path("/open/", handler).Use(logger)
+	conf.Path("/closed/", handler).Use(logger, deny)
+	conf.Register(eng)
+	Path(eng, "/path/", handler, "", logger)
+	Path(eng, "/path/closed/", handler, "", logger, deny)
+
+	tests := []struct {
+		path  string
+		code  int
+		order []string
+	}{
+		{path: "/open/", code: http.StatusOK, order: []string{"logger", "view", "logger done"}},
+		{path: "/closed/", code: http.StatusForbidden, order: []string{"logger", "deny", "logger done"}},
+		{path: "/path/", code: http.StatusOK, order: []string{"logger", "view", "logger done"}},
+		{path: "/path/closed/", code: http.StatusForbidden, order: []string{"logger", "deny", "logger done"}},
+	}
+	for _, test := range tests {
+		order = nil
+		w := httptest.NewRecorder()
+		eng.ServeHTTP(w, httptest.NewRequest(http.MethodGet, test.path, nil))
+		if w.Code != test.code {
+			t.Errorf("GET %s got status %d, want %d", test.path, w.Code, test.code)
+		}
+		if !reflect.DeepEqual(order, test.order) {
+			t.Errorf("GET %s ran %v, want %v", test.path, order, test.order)
+		}
+	}
+}
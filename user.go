@@ -1,8 +1,72 @@
 package django
 
+import "errors"
+
+var (
+	ErrorAnonymousUser = errors.New("anonymous users have no password")
+	ErrorNoUserID      = errors.New("user has no id: it does not implement IdentifiedUser")
+)
+
 type User interface {
 	ValidatePassword(password string) error
 	IsAuthenticated() bool
 	IsAdmin() bool
 	Username() string
 }
+
+// IdentifiedUser is implemented by users identified by an id, which sessions, tokens and ownership checks rely on.
+type IdentifiedUser interface {
+	User
+	// ID returns the id the user is looked up by with UserProvider.GetUser.
+	ID() uint
+}
+
+// userID returns the id of the user, false if the user does not implement IdentifiedUser.
+func userID(u User) (uint, bool) {
+	if i, ok := u.(IdentifiedUser); ok {
+		return i.ID(), true
+	}
+	return 0, false
+}
+
+// PermissionsUser is implemented by users granted permissions, identified by codenames such as "orders.change_order".
+type PermissionsUser interface {
+	User
+	// HasPerm returns true if the user was granted the permission.
+	HasPerm(codename string) bool
+}
+
+// hasPerm returns true if the user implements PermissionsUser and was granted the permission.
+func hasPerm(u User, codename string) bool {
+	if p, ok := u.(PermissionsUser); ok {
+		return p.HasPerm(codename)
+	}
+	return false
+}
+
+// AnonymousUser the User of requests that were not authenticated.
+type AnonymousUser struct{}
+
+func (AnonymousUser) ID() uint {
+	return 0
+}
+
+func (AnonymousUser) ValidatePassword(_ string) error {
+	return ErrorAnonymousUser
+}
+
+func (AnonymousUser) IsAuthenticated() bool {
+	return false
+}
+
+func (AnonymousUser) IsAdmin() bool {
+	return false
+}
+
+func (AnonymousUser) Username() string {
+	return ""
+}
+
+func (AnonymousUser) HasPerm(_ string) bool {
+	return false
+}
@@ -0,0 +1,338 @@
+package django
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/malijoe/djanGo-unchained/db"
+)
+
+// ViewSet groups the handlers of a resource under the standard action names.
+// the collection route serves list (GET) and create (POST); the detail route serves
+// retrieve (GET), update (PUT), partial_update (PATCH) and destroy (DELETE).
+// list, retrieve and destroy take no input: their serializer is only bound the parameters of the path.
+type ViewSet[R Serializer] struct {
+	list, retrieve, create, update, partialUpdate, destroy HandleFunc[R]
+	lookup                                                 string
+	opts                                                   []Opt[R]
+	actions                                                []action
+	instance                                               func(ctx *gin.Context) (*R, *APIError, error)
+}
+
+// action an extra route of a view set, mounted under the collection or detail route.
+type action struct {
+	detail bool
+	path   string
+	name   string
+	view   view
+}
+
+// viewSet is implemented by every ViewSet, regardless of its serializer.
+type viewSet interface {
+	routes(prefix, basename string) []*Route
+}
+
+// NewViewSet creates a view set; opts are applied to the handlers of both routes.
+func NewViewSet[R Serializer](opts ...Opt[R]) *ViewSet[R] {
+	return &ViewSet[R]{
+		lookup: ":id",
+		opts:   opts,
+	}
+}
+
+func (v *ViewSet[R]) List(handle HandleFunc[R]) *ViewSet[R] {
+	v.list = handle
+	return v
+}
+
+func (v *ViewSet[R]) Retrieve(handle HandleFunc[R]) *ViewSet[R] {
+	v.retrieve = handle
+	return v
+}
+
+func (v *ViewSet[R]) Create(handle HandleFunc[R]) *ViewSet[R] {
+	v.create = handle
+	return v
+}
+
+func (v *ViewSet[R]) Update(handle HandleFunc[R]) *ViewSet[R] {
+	v.update = handle
+	return v
+}
+
+func (v *ViewSet[R]) PartialUpdate(handle HandleFunc[R]) *ViewSet[R] {
+	v.partialUpdate = handle
+	return v
+}
+
+func (v *ViewSet[R]) Destroy(handle HandleFunc[R]) *ViewSet[R] {
+	v.destroy = handle
+	return v
+}
+
+// Lookup sets the path segment identifying an object on the detail route, ":id" by default.
+// converters are accepted, e.g. "<int:id>".
+func (v *ViewSet[R]) Lookup(lookup string) *ViewSet[R] {
+	v.lookup = lookup
+	return v
+}
+
+// DetailAction mounts view at `<lookup>/path` below the collection route.
+// the route is named `basename-name`, name defaulting to path.
+func (v *ViewSet[R]) DetailAction(path string, view view, name ...string) *ViewSet[R] {
+	v.actions = append(v.actions, newAction(true, path, view, name...))
+	return v
+}
+
+// ListAction mounts view at `path` below the collection route.
+// the route is named `basename-name`, name defaulting to path.
+func (v *ViewSet[R]) ListAction(path string, view view, name ...string) *ViewSet[R] {
+	v.actions = append(v.actions, newAction(false, path, view, name...))
+	return v
+}
+
+func newAction(detail bool, path string, view view, name ...string) action {
+	a := action{
+		detail: detail,
+		path:   path,
+		name:   path,
+		view:   view,
+	}
+	if len(name) > 0 {
+		a.name = name[0]
+	}
+	return a
+}
+
+// Register mounts the routes of the view set under prefix on the engine. the collection and detail
+// routes are named `basename-list` and `basename-detail` when a basename is given.
+func (v *ViewSet[R]) Register(eng *gin.Engine, prefix string, basename ...string) []*Route {
+	var name string
+	if len(basename) > 0 {
+		name = basename[0]
+	}
+	routes := v.routes(prefix, name)
+	for _, r := range routes {
+		r.mount(eng, "", "")
+	}
+	return routes
+}
+
+func (v *ViewSet[R]) routes(prefix, basename string) []*Route {
+	var routes []*Route
+	detailPath := joinPaths(prefix, v.lookup)
+
+	if v.list != nil || v.create != nil {
+		collection := NewHandler[R](v.opts...).Get(v.list).Post(v.create)
+		collection.noInput = []string{http.MethodGet}
+		routes = append(routes, newRoute(prefix, collection, routeName(basename, "list")))
+	}
+	if v.retrieve != nil || v.update != nil || v.partialUpdate != nil || v.destroy != nil {
+		detail := NewHandler[R](v.opts...).Get(v.retrieve).Put(v.update).Patch(v.partialUpdate).Delete(v.destroy)
+		detail.instance = v.instance
+		detail.noInput = []string{http.MethodGet, http.MethodDelete}
+		routes = append(routes, newRoute(detailPath, detail, routeName(basename, "detail")))
+	}
+	for _, a := range v.actions {
+		path := joinPaths(prefix, a.path)
+		if a.detail {
+			path = joinPaths(detailPath, a.path)
+		}
+		routes = append(routes, newRoute(path, a.view, routeName(basename, a.name)))
+	}
+	return routes
+}
+
+func routeName(basename, suffix string) string {
+	if basename == "" {
+		return ""
+	}
+	return basename + "-" + suffix
+}
+
+// ErrorNoIDField the entities of a model view set identify their objects by an `ID` integer field.
+var ErrorNoIDField = errors.New("entity has no integer ID field")
+
+// ModelSerializer a serializer that converts to and from the entity it represents.
+type ModelSerializer[T any] interface {
+	Serializer
+	db.DTO[T]
+}
+
+// NewModelViewSet creates a view set implementing every standard action on top of repo.
+// the detail route is looked up by `<int:id>`, which is set on the `ID` field of updated entities
+// whatever the body holds. partial updates are bound onto the stored object, leaving the fields
+// they do not carry unchanged. a models.Model is adapted with models.AsRepository.
+func NewModelViewSet[T any, S ModelSerializer[T]](repo db.BaseRepository[T], opts ...Opt[S]) *ViewSet[S] {
+	m := modelViewSet[T, S]{
+		repo: repo,
+	}
+	v := NewViewSet[S](opts...).
+		Lookup("<int:id>").
+		List(m.list).
+		Retrieve(m.retrieve).
+		Create(m.create).
+		Update(m.update).
+		PartialUpdate(m.partialUpdate).
+		Destroy(m.destroy)
+	v.instance = m.instance
+	return v
+}
+
+type modelViewSet[T any, S ModelSerializer[T]] struct {
+	repo db.BaseRepository[T]
+}
+
+func (m modelViewSet[T, S]) serialize(e T) S {
+	var s S
+	return s.FromEntity(e).(S)
+}
+
+// object fetches the object identified by id and checks the request's permissions to it.
+func (m modelViewSet[T, S]) object(ctx *gin.Context, id uint) (*T, *APIError, error) {
+	e, err := m.repo.Get(ctx.Request.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && e == nil) {
+		return nil, ErrNotFound(), err
+	}
+	if err != nil {
+		return nil, ErrInternal(), err
+	}
+	if apiErr := GetRequest(ctx).CheckObjectPermissions(e); apiErr != nil {
+		return nil, apiErr, nil
+	}
+	return e, nil, nil
+}
+
+// lookupID returns the value of the id path parameter.
+func lookupID(ctx *gin.Context) (uint, error) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 0)
+	return uint(id), err
+}
+
+// setID sets the `ID` field of the entity e points to.
+func setID(e any, id uint) error {
+	value := reflect.Indirect(reflect.ValueOf(e))
+	if value.Kind() != reflect.Struct {
+		return fmt.Errorf("%w: %T is not a struct", ErrorNoIDField, e)
+	}
+	field := value.FieldByName("ID")
+	if !field.IsValid() || !field.CanSet() {
+		return fmt.Errorf("%w: %T", ErrorNoIDField, e)
+	}
+	switch field.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		field.SetUint(uint64(id))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		field.SetInt(int64(id))
+	default:
+		return fmt.Errorf("%w: %T.ID is a %s", ErrorNoIDField, e, field.Kind())
+	}
+	return nil
+}
+
+func (m modelViewSet[T, S]) list(ctx *gin.Context, _ *S) (int, any, error) {
+	entities, err := m.repo.Find(ctx.Request.Context(), nil)
+	if err != nil {
+		return http.StatusInternalServerError, ErrInternal(), err
+	}
+	response := make([]S, len(entities))
+	for i := range entities {
+		response[i] = m.serialize(entities[i])
+	}
+	return http.StatusOK, response, nil
+}
+
+func (m modelViewSet[T, S]) retrieve(ctx *gin.Context, _ *S) (int, any, error) {
+	id, err := lookupID(ctx)
+	if err != nil {
+		return http.StatusNotFound, ErrNotFound(), err
+	}
+	e, apiErr, err := m.object(ctx, id)
+	if apiErr != nil {
+		return apiErr.Status, apiErr, err
+	}
+	return http.StatusOK, m.serialize(*e), nil
+}
+
+func (m modelViewSet[T, S]) create(ctx *gin.Context, serializer *S) (int, any, error) {
+	e, err := m.repo.Save(ctx.Request.Context(), (*serializer).ToEntity())
+	if err != nil {
+		return http.StatusInternalServerError, ErrInternal(), err
+	}
+	return http.StatusCreated, m.serialize(*e), nil
+}
+
+// instance loads the serialized object partial updates are bound onto.
+func (m modelViewSet[T, S]) instance(ctx *gin.Context) (*S, *APIError, error) {
+	id, err := lookupID(ctx)
+	if err != nil {
+		return nil, ErrNotFound(), err
+	}
+	e, apiErr, err := m.object(ctx, id)
+	if apiErr != nil {
+		return nil, apiErr, err
+	}
+	s := m.serialize(*e)
+	return &s, nil, nil
+}
+
+func (m modelViewSet[T, S]) update(ctx *gin.Context, serializer *S) (int, any, error) {
+	id, err := lookupID(ctx)
+	if err != nil {
+		return http.StatusNotFound, ErrNotFound(), err
+	}
+	if _, apiErr, err := m.object(ctx, id); apiErr != nil {
+		return apiErr.Status, apiErr, err
+	}
+	return m.save(ctx, id, serializer)
+}
+
+// partialUpdate saves serializer, bound onto the object by instance, which already checked the permissions to it.
+func (m modelViewSet[T, S]) partialUpdate(ctx *gin.Context, serializer *S) (int, any, error) {
+	id, err := lookupID(ctx)
+	if err != nil {
+		return http.StatusNotFound, ErrNotFound(), err
+	}
+	return m.save(ctx, id, serializer)
+}
+
+// save updates the object identified by id with serializer. every column is written, for fields cleared by the
+// request to be stored, when the repository is a db.ColumnUpdater; other repositories may skip zero values.
+func (m modelViewSet[T, S]) save(ctx *gin.Context, id uint, serializer *S) (int, any, error) {
+	entity := (*serializer).ToEntity()
+	if err := setID(&entity, id); err != nil {
+		return http.StatusInternalServerError, ErrInternal(), err
+	}
+	var (
+		e   *T
+		err error
+	)
+	if updater, ok := m.repo.(db.ColumnUpdater[T]); ok {
+		e, err = updater.UpdateColumns(ctx.Request.Context(), entity)
+	} else {
+		e, err = m.repo.Update(ctx.Request.Context(), entity)
+	}
+	if err != nil {
+		return http.StatusInternalServerError, ErrInternal(), err
+	}
+	return http.StatusOK, m.serialize(*e), nil
+}
+
+func (m modelViewSet[T, S]) destroy(ctx *gin.Context, _ *S) (int, any, error) {
+	id, err := lookupID(ctx)
+	if err != nil {
+		return http.StatusNotFound, ErrNotFound(), err
+	}
+	if _, apiErr, err := m.object(ctx, id); apiErr != nil {
+		return apiErr.Status, apiErr, err
+	}
+	if err = m.repo.Delete(ctx.Request.Context(), id); err != nil {
+		return http.StatusInternalServerError, ErrInternal(), err
+	}
+	return http.StatusNoContent, nil, nil
+}
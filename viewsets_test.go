@@ -0,0 +1,254 @@
+package django
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/malijoe/djanGo-unchained/db"
+)
+
+type book struct {
+	ID    uint
+	Title string
+}
+
+type bookSerializer struct {
+	ID    uint   `uri:"id" json:"id"`
+	Title string `json:"title"`
+}
+
+func (bookSerializer) Metadata() []Field {
+	return []Field{{Name: "id", ReadOnly: true}, {Name: "title"}}
+}
+
+func (bookSerializer) FromEntity(b book) any {
+	return bookSerializer{ID: b.ID, Title: b.Title}
+}
+
+func (s bookSerializer) ToEntity() book {
+	return book{ID: s.ID, Title: s.Title}
+}
+
+// newMemoryRepository a repository storing entities in memory, identified by the field id points to.
+func newMemoryRepository[T any](id func(*T) *uint) db.Repository[T] {
+	var (
+		entities = make(map[uint]T)
+		lastId   uint
+	)
+	return db.NewMockRepository[T](
+		db.WithSaveFn(func(e *T) (*T, error) {
+			lastId++
+			*id(e) = lastId
+			entities[lastId] = *e
+			return e, nil
+		}),
+		db.WithGetFn(func(i uint) (*T, error) {
+			e, ok := entities[i]
+			if !ok {
+				return nil, sql.ErrNoRows
+			}
+			return &e, nil
+		}),
+		db.WithFindFn(func(_ db.Specification) ([]T, error) {
+			response := make([]T, 0, len(entities))
+			for _, e := range entities {
+				response = append(response, e)
+			}
+			return response, nil
+		}),
+		// like db.Repository, Update skips zero values while UpdateColumns without columns writes all of them.
+		db.WithUpdateFn(func(e *T) (*T, error) {
+			stored, updated := reflect.ValueOf(entities[*id(e)]), reflect.ValueOf(e).Elem()
+			for i := 0; i < updated.NumField(); i++ {
+				if updated.Field(i).IsZero() {
+					updated.Field(i).Set(stored.Field(i))
+				}
+			}
+			entities[*id(e)] = *e
+			return e, nil
+		}),
+		db.WithUpdateColumnsFn(func(e *T, _ []string) (*T, error) {
+			entities[*id(e)] = *e
+			return e, nil
+		}),
+		db.WithDeleteFn[T](func(i uint) error {
+			delete(entities, i)
+			return nil
+		}),
+	)
+}
+
+func newBookRepository() db.Repository[book] {
+	return newMemoryRepository(func(b *book) *uint { return &b.ID })
+}
+
+func TestModelViewSet(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	eng := gin.New()
+	NewModelViewSet[book, bookSerializer](newBookRepository()).Register(eng, "/books", "book")
+
+	tests := []struct {
+		method, path, body string
+		code               int
+		response           string
+	}{
+		{method: http.MethodPost, path: "/books", body: `{"title":"dune"}`, code: http.StatusCreated, response: `{"id":1,"title":"dune"}`},
+		{method: http.MethodGet, path: "/books", code: http.StatusOK, response: `[{"id":1,"title":"dune"}]`},
+		{method: http.MethodGet, path: "/books/1", code: http.StatusOK, response: `{"id":1,"title":"dune"}`},
+		{method: http.MethodPut, path: "/books/1", body: `{"title":"emma"}`, code: http.StatusOK, response: `{"id":1,"title":"emma"}`},
+		{method: http.MethodPatch, path: "/books/2", body: `{"title":"emma"}`, code: http.StatusNotFound},
+		{method: http.MethodDelete, path: "/books/1", code: http.StatusNoContent},
+		{method: http.MethodGet, path: "/books/1", code: http.StatusNotFound},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+		if test.body != "" {
+			r.Header.Set("Content-Type", MIMEJSON)
+		}
+		eng.ServeHTTP(w, r)
+		if w.Code != test.code {
+			t.Errorf("%s %s got status %d, want %d", test.method, test.path, w.Code, test.code)
+			continue
+		}
+		if test.response != "" && w.Body.String() != test.response {
+			t.Errorf("%s %s got response %s, want %s", test.method, test.path, w.Body.String(), test.response)
+		}
+	}
+}
+
+type note struct {
+	ID          uint
+	Title, Body string
+}
+
+// noteSerializer does not bind the id of the path and requires a title.
+type noteSerializer struct {
+	ID    uint   `json:"id"`
+	Title string `json:"title" binding:"required"`
+	Body  string `json:"body"`
+}
+
+func (noteSerializer) Metadata() []Field {
+	return []Field{{Name: "id"}, {Name: "title"}, {Name: "body"}}
+}
+
+func (noteSerializer) FromEntity(n note) any {
+	return noteSerializer{ID: n.ID, Title: n.Title, Body: n.Body}
+}
+
+func (s noteSerializer) ToEntity() note {
+	return note{ID: s.ID, Title: s.Title, Body: s.Body}
+}
+
+func TestModelViewSetUpdate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	eng := gin.New()
+	NewModelViewSet[note, noteSerializer](newMemoryRepository(func(n *note) *uint { return &n.ID })).Register(eng, "/notes")
+
+	tests := []struct {
+		method, path, body string
+		code               int
+		response           string
+	}{
+		{method: http.MethodPost, path: "/notes", body: `{"title":"dune","body":"spice"}`, code: http.StatusCreated, response: `{"id":1,"title":"dune","body":"spice"}`},
+		{method: http.MethodPost, path: "/notes", body: `{"title":"emma","body":"highbury"}`, code: http.StatusCreated, response: `{"id":2,"title":"emma","body":"highbury"}`},
+		{method: http.MethodPatch, path: "/notes/1", body: `{}`, code: http.StatusOK, response: `{"id":1,"title":"dune","body":"spice"}`},
+		{method: http.MethodPatch, path: "/notes/1", body: `{"body":"arrakis"}`, code: http.StatusOK, response: `{"id":1,"title":"dune","body":"arrakis"}`},
+		{method: http.MethodPatch, path: "/notes/1", body: `{"id":2,"title":"messiah"}`, code: http.StatusOK, response: `{"id":1,"title":"messiah","body":"arrakis"}`},
+		{method: http.MethodPut, path: "/notes/1", body: `{"id":2,"title":"children"}`, code: http.StatusOK, response: `{"id":1,"title":"children","body":""}`},
+		{method: http.MethodGet, path: "/notes/1", code: http.StatusOK, response: `{"id":1,"title":"children","body":""}`},
+		{method: http.MethodPatch, path: "/notes/2", body: `{"body":""}`, code: http.StatusOK, response: `{"id":2,"title":"emma","body":""}`},
+		{method: http.MethodGet, path: "/notes/2", code: http.StatusOK, response: `{"id":2,"title":"emma","body":""}`},
+		{method: http.MethodPatch, path: "/notes/2", body: `{"title":""}`, code: http.StatusBadRequest},
+		{method: http.MethodPost, path: "/notes", body: `{"body":"untitled"}`, code: http.StatusBadRequest},
+		{method: http.MethodGet, path: "/notes", code: http.StatusOK},
+		{method: http.MethodPatch, path: "/notes/3", body: `{}`, code: http.StatusNotFound},
+		{method: http.MethodDelete, path: "/notes/2", code: http.StatusNoContent},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+		if test.body != "" {
+			r.Header.Set("Content-Type", MIMEJSON)
+		}
+		eng.ServeHTTP(w, r)
+		if w.Code != test.code {
+			t.Errorf("%s %s got status %d, want %d", test.method, test.path, w.Code, test.code)
+			continue
+		}
+		if test.response != "" && w.Body.String() != test.response {
+			t.Errorf("%s %s got response %s, want %s", test.method, test.path, w.Body.String(), test.response)
+		}
+	}
+}
+
+func TestViewSet(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	eng := gin.New()
+	action := func(name string) HandleFunc[bookSerializer] {
+		return func(ctx *gin.Context, _ *bookSerializer) (int, any, error) {
+			return http.StatusOK, name + " " + ctx.Param("id"), nil
+		}
+	}
+	NewViewSet[bookSerializer]().
+		List(action("list")).
+		Create(action("create")).
+		Retrieve(action("retrieve")).
+		Update(action("update")).
+		PartialUpdate(action("partial_update")).
+		Destroy(action("destroy")).
+		DetailAction("publish", NewHandler[bookSerializer]().Post(action("publish"))).
+		ListAction("recent", NewHandler[bookSerializer]().Get(action("recent")), "recent-books").
+		Register(eng, "/shelves", "shelf")
+
+	tests := []struct {
+		method, path string
+		code         int
+		response     string
+	}{
+		{method: http.MethodGet, path: "/shelves", code: http.StatusOK, response: `"list "`},
+		{method: http.MethodPost, path: "/shelves", code: http.StatusOK, response: `"create "`},
+		{method: http.MethodGet, path: "/shelves/1", code: http.StatusOK, response: `"retrieve 1"`},
+		{method: http.MethodPut, path: "/shelves/1", code: http.StatusOK, response: `"update 1"`},
+		{method: http.MethodPatch, path: "/shelves/1", code: http.StatusOK, response: `"partial_update 1"`},
+		{method: http.MethodDelete, path: "/shelves/1", code: http.StatusOK, response: `"destroy 1"`},
+		{method: http.MethodPost, path: "/shelves/1/publish", code: http.StatusOK, response: `"publish 1"`},
+		{method: http.MethodGet, path: "/shelves/recent", code: http.StatusOK, response: `"recent "`},
+		{method: http.MethodDelete, path: "/shelves", code: http.StatusNotFound},
+		{method: http.MethodGet, path: "/shelves/1/publish", code: http.StatusNotFound},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		eng.ServeHTTP(w, httptest.NewRequest(test.method, test.path, nil))
+		if w.Code != test.code {
+			t.Errorf("%s %s got status %d, want %d", test.method, test.path, w.Code, test.code)
+			continue
+		}
+		if test.response != "" && w.Body.String() != test.response {
+			t.Errorf("%s %s got response %s, want %s", test.method, test.path, w.Body.String(), test.response)
+		}
+	}
+
+	names := map[string]string{
+		"shelf-list":         "/shelves",
+		"shelf-detail":       "/shelves/1",
+		"shelf-publish":      "/shelves/1/publish",
+		"shelf-recent-books": "/shelves/recent",
+	}
+	for name, want := range names {
+		got, err := Reverse(name, map[string]any{"id": 1})
+		if err != nil {
+			t.Errorf("Reverse(%q) returned error %v", name, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("Reverse(%q) got %s, want %s", name, got, want)
+		}
+	}
+}